@@ -2,7 +2,9 @@ package auth
 
 import (
 	"errors"
+	"time"
 
+	"github.com/goldsproutapp/goldsprout-backend/config"
 	"github.com/goldsproutapp/goldsprout-backend/constants"
 	"github.com/goldsproutapp/goldsprout-backend/models"
 	"gorm.io/gorm"
@@ -49,10 +51,28 @@ func GenerateToken() string {
 func CreateToken(db *gorm.DB, user models.User, client string) string {
 	token := GenerateToken()
 	session := models.Session{
-		UserID:    user.ID,
-		TokenHash: Hash(token),
-		Client:    client,
+		UserID:     user.ID,
+		TokenHash:  Hash(token),
+		Client:     client,
+		LastUsedAt: time.Now(),
 	}
 	db.Save(&session)
 	return token
 }
+
+// Whether the session has passed its idle or absolute timeout.
+func SessionExpired(session models.Session, now time.Time) bool {
+	idle := config.SessionIdleTimeout()
+	absolute := config.SessionAbsoluteTimeout()
+	return (idle > 0 && now.Sub(session.LastUsedAt) > idle) || (absolute > 0 && now.Sub(session.CreatedAt) > absolute)
+}
+
+// Record that the session has been used. To avoid a write on every request, this is only
+// done once the previous use is older than constants.SESSION_TOUCH_INTERVAL.
+func TouchSession(db *gorm.DB, session *models.Session, now time.Time) {
+	if now.Sub(session.LastUsedAt) < constants.SESSION_TOUCH_INTERVAL {
+		return
+	}
+	session.LastUsedAt = now
+	db.Model(session).Update("last_used_at", now)
+}
@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/goldsproutapp/goldsprout-backend/config"
+	"github.com/goldsproutapp/goldsprout-backend/constants"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrOIDCDisabled     = errors.New("OIDC login is not configured")
+	ErrInvalidOIDCState = errors.New("Invalid or expired OIDC login state")
+	ErrOIDCLogin        = errors.New("OIDC login failed")
+	ErrNoOIDCUser       = errors.New("No user exists for the OIDC identity")
+)
+
+type OIDCIdentity struct {
+	Email      string
+	GivenName  string
+	FamilyName string
+}
+
+var (
+	oidcLock     sync.Mutex
+	oidcProvider *oidc.Provider
+)
+
+// The provider is discovered on first use, and again after a failure.
+func getOIDCProvider(ctx context.Context) (*oidc.Provider, error) {
+	oidcLock.Lock()
+	defer oidcLock.Unlock()
+	if oidcProvider != nil {
+		return oidcProvider, nil
+	}
+	provider, err := oidc.NewProvider(ctx, config.RequiredEnv(config.ENVKEY_OIDC_ISSUER))
+	if err != nil {
+		return nil, err
+	}
+	oidcProvider = provider
+	return provider, nil
+}
+
+func oidcConfig(provider *oidc.Provider) oauth2.Config {
+	return oauth2.Config{
+		ClientID:     config.RequiredEnv(config.ENVKEY_OIDC_CLIENT_ID),
+		ClientSecret: config.EnvOrDefault(config.ENVKEY_OIDC_CLIENT_SECRET, ""),
+		RedirectURL: config.EnvOrDefault(config.ENVKEY_OIDC_REDIRECT_URL,
+			config.EnvOrDefault(config.FRONTEND_BASE_URL, "")+"/login/oidc"),
+		Endpoint: provider.Endpoint(),
+		Scopes:   strings.Fields(config.EnvOrDefault(config.ENVKEY_OIDC_SCOPES, "openid email profile")),
+	}
+}
+
+// The identity provider's authorisation URL for a new login, using PKCE.
+func BeginOIDCLogin(ctx context.Context, db *gorm.DB) (string, error) {
+	if !config.OIDCEnabled() {
+		return "", ErrOIDCDisabled
+	}
+	provider, err := getOIDCProvider(ctx)
+	if err != nil {
+		return "", err
+	}
+	state := GenerateToken()
+	nonce := GenerateToken()
+	verifier := oauth2.GenerateVerifier()
+	db.Where("expires_at < ?", time.Now()).Delete(&models.OIDCLoginState{})
+	db.Create(&models.OIDCLoginState{
+		StateHash: Hash(state),
+		Nonce:     nonce,
+		Verifier:  verifier,
+		ExpiresAt: time.Now().Add(constants.OIDC_STATE_DURATION),
+	})
+	return oidcAuthCodeURL(provider, state, nonce, verifier), nil
+}
+
+func oidcAuthCodeURL(provider *oidc.Provider, state string, nonce string, verifier string) string {
+	cfg := oidcConfig(provider)
+	return cfg.AuthCodeURL(state, oidc.Nonce(nonce), oauth2.S256ChallengeOption(verifier))
+}
+
+// Complete the login for the authorisation code from the callback. The state can only be used once.
+func CompleteOIDCLogin(ctx context.Context, db *gorm.DB, code string, state string) (OIDCIdentity, error) {
+	if !config.OIDCEnabled() {
+		return OIDCIdentity{}, ErrOIDCDisabled
+	}
+	var pending models.OIDCLoginState
+	if db.Where(models.OIDCLoginState{StateHash: Hash(state)}).First(&pending).Error != nil {
+		return OIDCIdentity{}, ErrInvalidOIDCState
+	}
+	if db.Delete(&pending).RowsAffected != 1 || time.Now().After(pending.ExpiresAt) {
+		return OIDCIdentity{}, ErrInvalidOIDCState
+	}
+	provider, err := getOIDCProvider(ctx)
+	if err != nil {
+		return OIDCIdentity{}, err
+	}
+	return exchangeOIDCCode(ctx, provider, code, pending.Verifier, pending.Nonce)
+}
+
+// Exchange the code using the PKCE verifier, and verify the ID token and its nonce. Only
+// verified email addresses are accepted, since users are matched by email.
+func exchangeOIDCCode(ctx context.Context, provider *oidc.Provider, code string, verifier string, nonce string) (OIDCIdentity, error) {
+	cfg := oidcConfig(provider)
+	token, err := cfg.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return OIDCIdentity{}, ErrOIDCLogin
+	}
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return OIDCIdentity{}, ErrOIDCLogin
+	}
+	idToken, err := provider.Verifier(&oidc.Config{ClientID: cfg.ClientID}).Verify(ctx, rawIDToken)
+	if err != nil || idToken.Nonce != nonce {
+		return OIDCIdentity{}, ErrOIDCLogin
+	}
+	var claims struct {
+		Email         string `json:"email"`
+		EmailVerified *bool  `json:"email_verified"`
+		GivenName     string `json:"given_name"`
+		FamilyName    string `json:"family_name"`
+	}
+	if idToken.Claims(&claims) != nil || claims.Email == "" ||
+		claims.EmailVerified == nil || !*claims.EmailVerified {
+		return OIDCIdentity{}, ErrOIDCLogin
+	}
+	return OIDCIdentity{Email: claims.Email, GivenName: claims.GivenName, FamilyName: claims.FamilyName}, nil
+}
+
+// The user with the identity's email. An invited user who has not yet accepted their invitation
+// is activated if jitProvisioning is set; otherwise only active users can log in.
+func UserForOIDCIdentity(db *gorm.DB, identity OIDCIdentity, jitProvisioning bool) (models.User, error) {
+	var user models.User
+	if db.Where(models.User{Email: identity.Email}).Preload("AccessPermissions").First(&user).Error != nil {
+		return models.User{}, ErrNoOIDCUser
+	}
+	if user.Active {
+		return user, nil
+	}
+	if !jitProvisioning || user.InvitationToken == "" || user.IsDemoUser {
+		return models.User{}, ErrNoOIDCUser
+	}
+	user.Active = true
+	user.InvitationToken = ""
+	if user.FirstName == "" {
+		user.FirstName = identity.GivenName
+	}
+	if user.LastName == "" {
+		user.LastName = identity.FamilyName
+	}
+	db.Save(&user)
+	return user, nil
+}
@@ -0,0 +1,259 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/goldsproutapp/goldsprout-backend/config"
+)
+
+const testClientID = "goldsprout-test"
+
+// A minimal in-process OpenID provider, supporting the authorisation-code flow with PKCE.
+type mockIdP struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	claims map[string]any // added to every ID token
+
+	lock  sync.Mutex
+	codes map[string]mockAuthorisation
+}
+
+type mockAuthorisation struct {
+	challenge string
+	nonce     string
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &mockIdP{key: key, codes: map[string]mockAuthorisation{}, claims: map[string]any{
+		"email":          "user@example.com",
+		"email_verified": true,
+		"given_name":     "Test",
+		"family_name":    "User",
+	}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", idp.discovery)
+	mux.HandleFunc("/authorize", idp.authorize)
+	mux.HandleFunc("/token", idp.token)
+	mux.HandleFunc("/jwks", idp.jwks)
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func (idp *mockIdP) discovery(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]any{
+		"issuer":                                idp.server.URL,
+		"authorization_endpoint":                idp.server.URL + "/authorize",
+		"token_endpoint":                        idp.server.URL + "/token",
+		"jwks_uri":                              idp.server.URL + "/jwks",
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+	})
+}
+
+// Logs the user straight in, redirecting back with a code.
+func (idp *mockIdP) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("client_id") != testClientID || query.Get("code_challenge_method") != "S256" {
+		http.Error(w, "invalid_request", http.StatusBadRequest)
+		return
+	}
+	code := GenerateToken()
+	idp.lock.Lock()
+	idp.codes[code] = mockAuthorisation{challenge: query.Get("code_challenge"), nonce: query.Get("nonce")}
+	idp.lock.Unlock()
+	redirect, _ := url.Parse(query.Get("redirect_uri"))
+	params := url.Values{"code": {code}, "state": {query.Get("state")}}
+	redirect.RawQuery = params.Encode()
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (idp *mockIdP) token(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	idp.lock.Lock()
+	authorisation, ok := idp.codes[r.PostForm.Get("code")]
+	delete(idp.codes, r.PostForm.Get("code"))
+	idp.lock.Unlock()
+	verifier := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || base64.RawURLEncoding.EncodeToString(verifier[:]) != authorisation.challenge {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+	claims := map[string]any{
+		"iss":   idp.server.URL,
+		"sub":   "user-1",
+		"aud":   testClientID,
+		"iat":   time.Now().Unix(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"nonce": authorisation.nonce,
+	}
+	for k, v := range idp.claims {
+		claims[k] = v
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"access_token": GenerateToken(),
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+		"id_token":     idp.sign(claims),
+	})
+}
+
+func (idp *mockIdP) jwks(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]any{"keys": []map[string]string{{
+		"kty": "RSA",
+		"alg": "RS256",
+		"use": "sig",
+		"kid": "test",
+		"n":   base64.RawURLEncoding.EncodeToString(idp.key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(idp.key.E)).Bytes()),
+	}}})
+}
+
+func (idp *mockIdP) sign(claims map[string]any) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "test", "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, _ := rsa.SignPKCS1v15(rand.Reader, idp.key, crypto.SHA256, digest[:])
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// Configure OIDC login against the mock provider.
+func setupOIDC(t *testing.T) *mockIdP {
+	idp := newMockIdP(t)
+	t.Setenv(config.ENVKEY_OIDC_ISSUER, idp.server.URL)
+	t.Setenv(config.ENVKEY_OIDC_CLIENT_ID, testClientID)
+	t.Setenv(config.ENVKEY_OIDC_REDIRECT_URL, "http://frontend.test/login/oidc")
+	oidcLock.Lock()
+	oidcProvider = nil
+	oidcLock.Unlock()
+	t.Cleanup(func() {
+		oidcLock.Lock()
+		oidcProvider = nil
+		oidcLock.Unlock()
+	})
+	return idp
+}
+
+// Follow the authorisation URL as the browser would, returning the code and state from the callback.
+func authorise(t *testing.T, authURL string) (string, string) {
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	res, err := client.Get(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusFound {
+		t.Fatalf("authorisation failed with status %d", res.StatusCode)
+	}
+	callback, err := url.Parse(res.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if callback.Host != "frontend.test" {
+		t.Fatalf("redirected to %s", callback)
+	}
+	return callback.Query().Get("code"), callback.Query().Get("state")
+}
+
+func beginTestLogin(t *testing.T, ctx context.Context, state string, nonce string, verifier string) string {
+	provider, err := getOIDCProvider(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, returnedState := authorise(t, oidcAuthCodeURL(provider, state, nonce, verifier))
+	if returnedState != state {
+		t.Fatalf("state: got %q, expected %q", returnedState, state)
+	}
+	return code
+}
+
+func TestOIDCLogin(t *testing.T) {
+	setupOIDC(t)
+	ctx := context.Background()
+	verifier := GenerateToken()
+	code := beginTestLogin(t, ctx, "state", "nonce", verifier)
+
+	provider, _ := getOIDCProvider(ctx)
+	identity, err := exchangeOIDCCode(ctx, provider, code, verifier, "nonce")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := OIDCIdentity{Email: "user@example.com", GivenName: "Test", FamilyName: "User"}
+	if identity != expected {
+		t.Errorf("identity: got %+v, expected %+v", identity, expected)
+	}
+	// Codes are single-use.
+	if _, err := exchangeOIDCCode(ctx, provider, code, verifier, "nonce"); err == nil {
+		t.Error("code was accepted twice")
+	}
+}
+
+func TestOIDCLoginRejectsWrongVerifier(t *testing.T) {
+	setupOIDC(t)
+	ctx := context.Background()
+	code := beginTestLogin(t, ctx, "state", "nonce", GenerateToken())
+	provider, _ := getOIDCProvider(ctx)
+	if _, err := exchangeOIDCCode(ctx, provider, code, GenerateToken(), "nonce"); err != ErrOIDCLogin {
+		t.Errorf("got %v, expected ErrOIDCLogin", err)
+	}
+}
+
+func TestOIDCLoginRejectsWrongNonce(t *testing.T) {
+	setupOIDC(t)
+	ctx := context.Background()
+	verifier := GenerateToken()
+	code := beginTestLogin(t, ctx, "state", "nonce", verifier)
+	provider, _ := getOIDCProvider(ctx)
+	if _, err := exchangeOIDCCode(ctx, provider, code, verifier, "other"); err != ErrOIDCLogin {
+		t.Errorf("got %v, expected ErrOIDCLogin", err)
+	}
+}
+
+func TestOIDCLoginRequiresVerifiedEmail(t *testing.T) {
+	for name, verified := range map[string]any{"unverified": false, "missing": nil} {
+		t.Run(name, func(t *testing.T) {
+			idp := setupOIDC(t)
+			if verified == nil {
+				delete(idp.claims, "email_verified")
+			} else {
+				idp.claims["email_verified"] = verified
+			}
+			ctx := context.Background()
+			verifier := GenerateToken()
+			code := beginTestLogin(t, ctx, "state", "nonce", verifier)
+			provider, _ := getOIDCProvider(ctx)
+			if _, err := exchangeOIDCCode(ctx, provider, code, verifier, "nonce"); err != ErrOIDCLogin {
+				t.Errorf("got %v, expected ErrOIDCLogin", err)
+			}
+		})
+	}
+}
+
+func TestOIDCDisabled(t *testing.T) {
+	t.Setenv(config.ENVKEY_OIDC_ISSUER, "")
+	if _, err := BeginOIDCLogin(context.Background(), nil); err != ErrOIDCDisabled {
+		t.Errorf("got %v, expected ErrOIDCDisabled", err)
+	}
+}
@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/goldsproutapp/goldsprout-backend/constants"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"gorm.io/gorm"
+)
+
+var ErrInvalidResetToken = errors.New("Invalid or expired password reset token")
+
+// Issue a reset token for the user, invalidating any previous ones. Only the token's hash is stored.
+func CreatePasswordResetToken(db *gorm.DB, user models.User) string {
+	db.Where("user_id = ? AND used_at IS NULL", user.ID).Delete(&models.PasswordResetToken{})
+	token := GenerateToken()
+	db.Create(&models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: Hash(token),
+		ExpiresAt: time.Now().Add(constants.PASSWORD_RESET_DURATION),
+	})
+	return token
+}
+
+// Mark the token as used, returning its user if it was valid.
+func UsePasswordResetToken(db *gorm.DB, token string) (models.User, error) {
+	var reset models.PasswordResetToken
+	res := db.Where("token_hash = ? AND used_at IS NULL", Hash(token)).Preload("User").First(&reset)
+	if res.Error != nil || time.Now().After(reset.ExpiresAt) {
+		return models.User{}, ErrInvalidResetToken
+	}
+	now := time.Now()
+	// Conditional on the token being unused, so that concurrent requests cannot both succeed.
+	if db.Model(&reset).Where("used_at IS NULL").Update("used_at", &now).RowsAffected != 1 {
+		return models.User{}, ErrInvalidResetToken
+	}
+	return reset.User, nil
+}
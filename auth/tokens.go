@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/goldsproutapp/goldsprout-backend/constants"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const apiTokenAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+
+var ErrInvalidAPIToken = errors.New("Invalid or expired API token")
+
+func GenerateAPIToken() string {
+	return constants.API_TOKEN_PREFIX + GenerateSecureString(apiTokenAlphabet, constants.TOKEN_LENGTH)
+}
+
+// Look up an API token, recording the use if it is valid.
+func AuthenticateAPIToken(db *gorm.DB, token string) (models.APIToken, error) {
+	var apiToken models.APIToken
+	res := db.Where(models.APIToken{TokenHash: Hash(token)}).First(&apiToken)
+	if res.Error != nil {
+		return models.APIToken{}, ErrInvalidAPIToken
+	}
+	now := time.Now()
+	if apiToken.ExpiresAt != nil && now.After(*apiToken.ExpiresAt) {
+		return models.APIToken{}, ErrInvalidAPIToken
+	}
+	// UpdateColumns skips the hooks, so the token's account restrictions aren't rewritten.
+	db.Model(&apiToken).UpdateColumns(map[string]any{
+		"last_used_at": now,
+		"use_count":    clause.Expr{SQL: "use_count + 1"},
+	})
+	apiToken.LastUsedAt = &now
+	apiToken.UseCount++
+	return apiToken, nil
+}
+
+// Whether a token with the given scope can make a request with the given method. permitted is
+// the additional scope which the route accepts for writes, if any.
+func TokenScopeAllows(scope string, method string, permitted string) bool {
+	if scope == constants.API_SCOPE_ADMIN {
+		return true
+	}
+	if method == http.MethodGet || method == http.MethodHead {
+		return true
+	}
+	return scope != constants.API_SCOPE_READ && scope == permitted
+}
+
+// Record a request made with the token.
+func LogAPITokenUse(db *gorm.DB, token models.APIToken, method string, path string, clientIP string, status int) {
+	db.Create(&models.APITokenUse{
+		APITokenID: token.ID,
+		Method:     method,
+		Path:       path,
+		ClientIP:   clientIP,
+		Status:     status,
+	})
+}
+
+func TokenAllowsAccount(token models.APIToken, accountID uint) bool {
+	return len(token.Accounts) == 0 || slices.Contains(token.Accounts, accountID)
+}
@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/goldsproutapp/goldsprout-backend/constants"
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// A random string drawn from alphabet, using a cryptographically secure source.
+func GenerateSecureString(alphabet string, length int) string {
+	out := make([]byte, length)
+	max := big.NewInt(int64(len(alphabet)))
+	for i := range out {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			panic(err)
+		}
+		out[i] = alphabet[n.Int64()]
+	}
+	return string(out)
+}
+
+func GenerateTOTPSecret() string {
+	secret := make([]byte, 20) // 160 bits, as recommended by RFC 4226
+	if _, err := rand.Read(secret); err != nil {
+		panic(err)
+	}
+	return totpEncoding.EncodeToString(secret)
+}
+
+func TOTPStep(t time.Time) int64 {
+	return t.Unix() / constants.TOTP_PERIOD
+}
+
+// The code for the given time step, as defined by RFC 6238.
+func TOTPCode(secret string, step int64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", err
+	}
+	counter := make([]byte, 8)
+	binary.BigEndian.PutUint64(counter, uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter)
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(1)
+	for i := 0; i < constants.TOTP_DIGITS; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", constants.TOTP_DIGITS, value%mod), nil
+}
+
+// The step matched by the code, if it is valid at time t and later than lastStep.
+// Rejecting steps at or before lastStep prevents a code from being used twice.
+func ValidateTOTP(secret string, code string, t time.Time, lastStep int64) (int64, bool) {
+	current := TOTPStep(t)
+	for step := current - constants.TOTP_SKEW; step <= current+constants.TOTP_SKEW; step++ {
+		if step <= lastStep {
+			continue
+		}
+		expected, err := TOTPCode(secret, step)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+func TOTPURI(secret string, email string) string {
+	label := url.PathEscape(constants.TOTP_ISSUER + ":" + email)
+	params := url.Values{
+		"secret":    {secret},
+		"issuer":    {constants.TOTP_ISSUER},
+		"algorithm": {"SHA1"},
+		"digits":    {fmt.Sprint(constants.TOTP_DIGITS)},
+		"period":    {fmt.Sprint(constants.TOTP_PERIOD)},
+	}
+	return fmt.Sprintf("otpauth://totp/%s?%s", label, params.Encode())
+}
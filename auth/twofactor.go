@@ -0,0 +1,161 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/goldsproutapp/goldsprout-backend/constants"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"gorm.io/gorm"
+)
+
+const recoveryCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789" // without easily confused characters
+
+var ErrInvalidChallenge = errors.New("Invalid or expired login challenge")
+var ErrTwoFactorLocked = errors.New("Too many failed two-factor attempts")
+
+func GetInstanceSettings(db *gorm.DB) models.InstanceSettings {
+	var settings models.InstanceSettings
+	db.FirstOrCreate(&settings, models.InstanceSettings{ID: 1})
+	return settings
+}
+
+// Whether the user has to complete a second step to log in.
+func RequiresTwoFactor(db *gorm.DB, user models.User) bool {
+	return user.TOTPEnabled || (user.IsAdmin && GetInstanceSettings(db).RequireAdminTwoFactor)
+}
+
+// Whether the user has failed too many second-factor codes recently to be given another challenge.
+func TwoFactorLocked(user models.User, now time.Time) bool {
+	return user.TwoFactorFailures >= constants.TWO_FACTOR_MAX_FAILURES &&
+		user.TwoFactorFailedAt != nil && now.Sub(*user.TwoFactorFailedAt) < constants.TWO_FACTOR_LOCKOUT
+}
+
+// Count a failed code against the user. Failures older than the lockout are forgotten.
+func RecordTwoFactorFailure(db *gorm.DB, user *models.User) {
+	now := time.Now()
+	if user.TwoFactorFailedAt == nil || now.Sub(*user.TwoFactorFailedAt) >= constants.TWO_FACTOR_LOCKOUT {
+		user.TwoFactorFailures = 0
+	}
+	user.TwoFactorFailures++
+	user.TwoFactorFailedAt = &now
+	db.Model(user).Select("two_factor_failures", "two_factor_failed_at").Updates(user)
+}
+
+func ResetTwoFactorFailures(db *gorm.DB, user *models.User) {
+	if user.TwoFactorFailures == 0 {
+		return
+	}
+	user.TwoFactorFailures = 0
+	db.Model(user).Update("two_factor_failures", 0)
+}
+
+func CreateLoginChallenge(db *gorm.DB, user models.User, client string) (string, error) {
+	if TwoFactorLocked(user, time.Now()) {
+		return "", ErrTwoFactorLocked
+	}
+	token := GenerateToken()
+	challenge := models.LoginChallenge{
+		UserID:    user.ID,
+		TokenHash: Hash(token),
+		Client:    client,
+		Enrol:     !user.TOTPEnabled,
+		ExpiresAt: time.Now().Add(constants.LOGIN_CHALLENGE_DURATION),
+	}
+	db.Create(&challenge)
+	return token, nil
+}
+
+// The challenge and its user, if the challenge is still valid. Each lookup counts as an attempt.
+func UseLoginChallenge(db *gorm.DB, token string) (models.LoginChallenge, error) {
+	var challenge models.LoginChallenge
+	res := db.Where(models.LoginChallenge{TokenHash: Hash(token)}).Preload("User.AccessPermissions").First(&challenge)
+	if res.Error != nil {
+		return models.LoginChallenge{}, ErrInvalidChallenge
+	}
+	if time.Now().After(challenge.ExpiresAt) || challenge.Attempts >= constants.LOGIN_CHALLENGE_ATTEMPTS {
+		db.Delete(&challenge)
+		return models.LoginChallenge{}, ErrInvalidChallenge
+	}
+	if TwoFactorLocked(challenge.User, time.Now()) {
+		db.Delete(&challenge)
+		return models.LoginChallenge{}, ErrTwoFactorLocked
+	}
+	challenge.Attempts++
+	db.Model(&challenge).Update("attempts", challenge.Attempts)
+	return challenge, nil
+}
+
+// Start (or restart) enrolment, returning the new secret. Two-factor authentication is not
+// enabled until a code for the secret has been verified.
+func BeginTOTPEnrolment(db *gorm.DB, user *models.User) string {
+	user.TOTPSecret = GenerateTOTPSecret()
+	user.TOTPLastStep = 0
+	db.Model(user).Select("totp_secret", "totp_last_step").Updates(user)
+	return user.TOTPSecret
+}
+
+// Check a TOTP code against the user's secret, recording its time step if valid.
+func VerifyTOTP(db *gorm.DB, user *models.User, code string) bool {
+	if user.TOTPSecret == "" {
+		return false
+	}
+	step, ok := ValidateTOTP(user.TOTPSecret, strings.TrimSpace(code), time.Now(), user.TOTPLastStep)
+	if !ok {
+		return false
+	}
+	user.TOTPLastStep = step
+	db.Model(user).Update("totp_last_step", step)
+	return true
+}
+
+// Check a TOTP or unused recovery code. A recovery code is marked as used.
+func VerifySecondFactor(db *gorm.DB, user *models.User, code string) bool {
+	if VerifyTOTP(db, user, code) {
+		return true
+	}
+	normalised := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	var codes []models.RecoveryCode
+	db.Where("user_id = ? AND used_at IS NULL", user.ID).Find(&codes)
+	hash := Hash(normalised)
+	for _, recovery := range codes {
+		if subtle.ConstantTimeCompare([]byte(recovery.CodeHash), []byte(hash)) == 1 {
+			now := time.Now()
+			db.Model(&recovery).Update("used_at", &now)
+			return true
+		}
+	}
+	return false
+}
+
+// Enable two-factor authentication, replacing any existing recovery codes. The new codes are returned
+// and only their hashes are stored.
+func EnableTwoFactor(db *gorm.DB, user *models.User) []string {
+	user.TOTPEnabled = true
+	db.Model(user).Update("totp_enabled", true)
+	return RegenerateRecoveryCodes(db, *user)
+}
+
+func RegenerateRecoveryCodes(db *gorm.DB, user models.User) []string {
+	db.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{})
+	codes := make([]string, constants.RECOVERY_CODE_COUNT)
+	objs := make([]models.RecoveryCode, constants.RECOVERY_CODE_COUNT)
+	for i := range codes {
+		code := GenerateSecureString(recoveryCodeAlphabet, constants.RECOVERY_CODE_LENGTH)
+		objs[i] = models.RecoveryCode{UserID: user.ID, CodeHash: Hash(code)}
+		half := constants.RECOVERY_CODE_LENGTH / 2
+		codes[i] = code[:half] + "-" + code[half:]
+	}
+	db.Create(&objs)
+	return codes
+}
+
+func DisableTwoFactor(db *gorm.DB, user *models.User) {
+	user.TOTPEnabled = false
+	user.TOTPSecret = ""
+	user.TOTPLastStep = 0
+	db.Model(user).Select("totp_enabled", "totp_secret", "totp_last_step").Updates(user)
+	db.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{})
+}
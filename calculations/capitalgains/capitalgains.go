@@ -0,0 +1,130 @@
+package capitalgains
+
+import (
+	"sort"
+	"time"
+
+	"github.com/goldsproutapp/goldsprout-backend/constants"
+	"github.com/goldsproutapp/goldsprout-backend/database"
+	"github.com/goldsproutapp/goldsprout-backend/lib/extraction/times"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/goldsproutapp/goldsprout-backend/util"
+	"github.com/shopspring/decimal"
+)
+
+type holdingKey struct {
+	UserID  uint
+	StockID uint
+}
+
+// Acquisitions and disposals from the unit changes between each holding's snapshots, grouped
+// per person and stock, since holdings of the same stock across (non-sheltered) accounts are pooled.
+// Every unit change is included, so that the pools always match the recorded units.
+func buildHoldingEvents(snapshots []models.StockSnapshot) map[holdingKey][]holdingEvent {
+	holdings := map[string][]models.StockSnapshot{}
+	accountLast := map[uint]time.Time{}
+	for _, snapshot := range snapshots {
+		holdings[snapshot.Key()] = append(holdings[snapshot.Key()], snapshot)
+		if snapshot.Date.After(accountLast[snapshot.AccountID]) {
+			accountLast[snapshot.AccountID] = snapshot.Date
+		}
+	}
+	hundred := decimal.NewFromInt(100)
+	events := map[holdingKey][]holdingEvent{}
+	for _, holding := range holdings {
+		sort.SliceStable(holding, func(i, j int) bool {
+			return holding[i].Date.Before(holding[j].Date)
+		})
+		key := holdingKey{UserID: holding[0].UserID, StockID: holding[0].StockID}
+		add := func(date time.Time, units decimal.Decimal, price decimal.Decimal, attribution uint) {
+			events[key] = append(events[key], holdingEvent{
+				Date:  date,
+				Units: units,
+				Value: units.Abs().Mul(price).Div(hundred),
+				Fee:   attribution == constants.TransAttrIncomeFee && units.IsNegative(),
+			})
+		}
+		units := decimal.Zero
+		for _, snapshot := range holding {
+			if change := snapshot.Units.Sub(units); !change.IsZero() {
+				add(snapshot.Date, change, snapshot.Price, snapshot.TransactionAttribution)
+			}
+			units = snapshot.Units
+		}
+		last := holding[len(holding)-1]
+		if units.IsPositive() && accountLast[last.AccountID].After(last.Date) {
+			// The holding has been sold without a zero-entry being recorded.
+			add(last.Date, units.Neg(), last.Price, constants.TransAttrBuySell)
+		}
+	}
+	for _, keyEvents := range events {
+		sort.SliceStable(keyEvents, func(i, j int) bool {
+			return keyEvents[i].Date.Before(keyEvents[j].Date)
+		})
+	}
+	return events
+}
+
+func summarise(disposals []Disposal) ([]string, map[string]TaxYearSummary) {
+	summary := map[string]TaxYearSummary{}
+	for _, disposal := range disposals {
+		s, ok := summary[disposal.TaxYear]
+		if !ok {
+			s = TaxYearSummary{
+				Proceeds:      decimal.Zero,
+				AllowableCost: decimal.Zero,
+				Gains:         decimal.Zero,
+				Losses:        decimal.Zero,
+				NetGain:       decimal.Zero,
+			}
+		}
+		s.Proceeds = s.Proceeds.Add(disposal.Proceeds)
+		s.AllowableCost = s.AllowableCost.Add(disposal.AllowableCost)
+		if disposal.Gain.IsNegative() {
+			s.Losses = s.Losses.Add(disposal.Gain.Neg())
+		} else {
+			s.Gains = s.Gains.Add(disposal.Gain)
+		}
+		s.NetGain = s.Gains.Sub(s.Losses)
+		s.DisposalCount += 1
+		summary[disposal.TaxYear] = s
+	}
+	return times.ListTaxYears(util.MapKeys(summary)), summary
+}
+
+// Snapshots should cover the full history of each holding so that acquisitions
+// before the reporting window are included in the cost basis; the filter's dates
+// only restrict which disposals are reported.
+func CalculateCapitalGains(filter database.StockFilter, method string, snapshots []models.StockSnapshot) CapitalGainsReport {
+	taxable := []models.StockSnapshot{}
+	for _, snapshot := range snapshots {
+		if !snapshot.Account.IsTaxSheltered() {
+			taxable = append(taxable, snapshot)
+		}
+	}
+	matchingFunction := MatchingFunctionByName(method)
+
+	disposals := []Disposal{}
+	for key, events := range buildHoldingEvents(taxable) {
+		for _, disposal := range matchingFunction(events) {
+			if filter.LowerDate.Unix() != 0 && disposal.Date.Before(filter.LowerDate) ||
+				filter.UpperDate.Unix() != 0 && disposal.Date.After(filter.UpperDate) {
+				continue
+			}
+			disposal.UserID = key.UserID
+			disposal.StockID = key.StockID
+			disposal.TaxYear = times.FormatTaxYear(disposal.Date)
+			disposals = append(disposals, disposal)
+		}
+	}
+	sort.SliceStable(disposals, func(i, j int) bool {
+		return disposals[i].Date.Before(disposals[j].Date)
+	})
+	taxYears, summary := summarise(disposals)
+	return CapitalGainsReport{
+		Method:    method,
+		TaxYears:  taxYears,
+		Summary:   summary,
+		Disposals: disposals,
+	}
+}
@@ -0,0 +1,199 @@
+package capitalgains
+
+import (
+	"time"
+
+	"github.com/goldsproutapp/goldsprout-backend/util"
+	"github.com/shopspring/decimal"
+)
+
+var matchingMethods = map[string]MatchingFunction{
+	"fifo":    MatchFIFO,
+	"average": MatchAverageCost,
+	"uk":      MatchUK,
+}
+
+func MatchingFunctionByName(name string) MatchingFunction {
+	return matchingMethods[name]
+}
+
+func newDisposal(date time.Time, units decimal.Decimal, proceeds decimal.Decimal, cost decimal.Decimal) Disposal {
+	cost = cost.Round(2)
+	return Disposal{
+		Date:          date,
+		Units:         units,
+		Proceeds:      proceeds,
+		AllowableCost: cost,
+		Gain:          proceeds.Sub(cost),
+	}
+}
+
+type lot struct {
+	Units decimal.Decimal
+	Cost  decimal.Decimal
+}
+
+// Take up to `units` from the lot, returning the units taken and their cost.
+func (l *lot) take(units decimal.Decimal) (decimal.Decimal, decimal.Decimal) {
+	if !l.Units.IsPositive() {
+		return decimal.Zero, decimal.Zero
+	}
+	taken := decimal.Min(units, l.Units)
+	cost := l.Cost.Mul(taken).Div(l.Units)
+	l.Units = l.Units.Sub(taken)
+	l.Cost = l.Cost.Sub(cost)
+	return taken, cost
+}
+
+// Remove up to `units` from the lot without reducing its cost, returning the units removed.
+func (l *lot) reduce(units decimal.Decimal) decimal.Decimal {
+	if !l.Units.IsPositive() {
+		return decimal.Zero
+	}
+	taken := decimal.Min(units, l.Units)
+	l.Units = l.Units.Sub(taken)
+	if !l.Units.IsPositive() {
+		l.Cost = decimal.Zero
+	}
+	return taken
+}
+
+// Disposals are matched against the oldest acquisitions first.
+func MatchFIFO(events []holdingEvent) []Disposal {
+	lots := []*lot{}
+	out := []Disposal{}
+	for _, event := range events {
+		if event.Units.IsPositive() {
+			lots = append(lots, &lot{Units: event.Units, Cost: event.Value})
+			continue
+		}
+		if event.Fee {
+			remaining := event.Units.Neg()
+			for remaining.IsPositive() && len(lots) > 0 {
+				remaining = remaining.Sub(lots[0].reduce(remaining))
+				if !lots[0].Units.IsPositive() {
+					lots = lots[1:]
+				}
+			}
+			continue
+		}
+		remaining := event.Units.Neg()
+		cost := decimal.Zero
+		for remaining.IsPositive() && len(lots) > 0 {
+			taken, takenCost := lots[0].take(remaining)
+			remaining = remaining.Sub(taken)
+			cost = cost.Add(takenCost)
+			if !lots[0].Units.IsPositive() {
+				lots = lots[1:]
+			}
+		}
+		out = append(out, newDisposal(event.Date, event.Units.Neg(), event.Value, cost))
+	}
+	return out
+}
+
+// Disposals are matched against the average cost of all units held at the time.
+func MatchAverageCost(events []holdingEvent) []Disposal {
+	pool := lot{Units: decimal.Zero, Cost: decimal.Zero}
+	out := []Disposal{}
+	for _, event := range events {
+		if event.Units.IsPositive() {
+			pool.Units = pool.Units.Add(event.Units)
+			pool.Cost = pool.Cost.Add(event.Value)
+			continue
+		}
+		if event.Fee {
+			pool.reduce(event.Units.Neg())
+			continue
+		}
+		_, cost := pool.take(event.Units.Neg())
+		out = append(out, newDisposal(event.Date, event.Units.Neg(), event.Value, cost))
+	}
+	return out
+}
+
+type dayEvents struct {
+	Date         time.Time
+	Acquired     lot
+	Fees         decimal.Decimal // units
+	Disposed     decimal.Decimal
+	Proceeds     decimal.Decimal
+	Unmatched    decimal.Decimal
+	MatchedCost  decimal.Decimal
+	HasDisposals bool
+}
+
+func groupByDay(events []holdingEvent) []*dayEvents {
+	days := []*dayEvents{}
+	dayMap := map[time.Time]*dayEvents{}
+	for _, event := range events {
+		date := time.Date(event.Date.Year(), event.Date.Month(), event.Date.Day(), 0, 0, 0, 0, time.UTC)
+		if !util.ContainsKey(dayMap, date) {
+			dayMap[date] = &dayEvents{
+				Date:        date,
+				Acquired:    lot{Units: decimal.Zero, Cost: decimal.Zero},
+				Fees:        decimal.Zero,
+				Disposed:    decimal.Zero,
+				Proceeds:    decimal.Zero,
+				MatchedCost: decimal.Zero,
+			}
+			days = append(days, dayMap[date])
+		}
+		day := dayMap[date]
+		if event.Fee {
+			day.Fees = day.Fees.Add(event.Units.Neg())
+		} else if event.Units.IsPositive() {
+			day.Acquired.Units = day.Acquired.Units.Add(event.Units)
+			day.Acquired.Cost = day.Acquired.Cost.Add(event.Value)
+		} else {
+			day.Disposed = day.Disposed.Add(event.Units.Neg())
+			day.Proceeds = day.Proceeds.Add(event.Value)
+			day.HasDisposals = true
+		}
+	}
+	for _, day := range days {
+		day.Unmatched = day.Disposed
+	}
+	return days
+}
+
+// UK share identification rules (TCGA 1992 s105/s106A): disposals are matched first
+// against acquisitions on the same day, then against acquisitions in the following
+// 30 days, and finally against the section 104 pool. Fees are taken from the pool.
+func MatchUK(events []holdingEvent) []Disposal {
+	days := groupByDay(events)
+	match := func(day *dayEvents, acquisition *lot) {
+		taken, cost := acquisition.take(day.Unmatched)
+		day.Unmatched = day.Unmatched.Sub(taken)
+		day.MatchedCost = day.MatchedCost.Add(cost)
+	}
+	for _, day := range days {
+		if day.HasDisposals {
+			match(day, &day.Acquired)
+		}
+	}
+	for i, day := range days {
+		if !day.HasDisposals {
+			continue
+		}
+		for _, later := range days[i+1:] {
+			if later.Date.After(day.Date.AddDate(0, 0, 30)) || !day.Unmatched.IsPositive() {
+				break
+			}
+			match(day, &later.Acquired)
+		}
+	}
+	pool := lot{Units: decimal.Zero, Cost: decimal.Zero}
+	out := []Disposal{}
+	for _, day := range days {
+		pool.Units = pool.Units.Add(day.Acquired.Units)
+		pool.Cost = pool.Cost.Add(day.Acquired.Cost)
+		pool.reduce(day.Fees)
+		if !day.HasDisposals {
+			continue
+		}
+		match(day, &pool)
+		out = append(out, newDisposal(day.Date, day.Disposed, day.Proceeds, day.MatchedCost))
+	}
+	return out
+}
@@ -0,0 +1,12 @@
+package capitalgains
+
+import (
+	"slices"
+
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/goldsproutapp/goldsprout-backend/util"
+)
+
+func IsCapitalGainsQueryValid(query models.CapitalGainsRequestQuery) bool {
+	return slices.Contains(util.MapKeys(matchingMethods), query.Method)
+}
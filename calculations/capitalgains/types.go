@@ -0,0 +1,44 @@
+package capitalgains
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type Disposal struct {
+	Date          time.Time       `json:"date"`
+	TaxYear       string          `json:"tax_year"`
+	UserID        uint            `json:"user_id"`
+	StockID       uint            `json:"stock_id"`
+	Units         decimal.Decimal `json:"units"`
+	Proceeds      decimal.Decimal `json:"proceeds"`
+	AllowableCost decimal.Decimal `json:"allowable_cost"`
+	Gain          decimal.Decimal `json:"gain"`
+}
+
+type TaxYearSummary struct {
+	Proceeds      decimal.Decimal `json:"proceeds"`
+	AllowableCost decimal.Decimal `json:"allowable_cost"`
+	Gains         decimal.Decimal `json:"gains"`
+	Losses        decimal.Decimal `json:"losses"`
+	NetGain       decimal.Decimal `json:"net_gain"`
+	DisposalCount int             `json:"disposal_count"`
+}
+
+type CapitalGainsReport struct {
+	Method    string                    `json:"method"`
+	TaxYears  []string                  `json:"tax_years"`
+	Summary   map[string]TaxYearSummary `json:"summary"`
+	Disposals []Disposal                `json:"disposals"`
+}
+
+// A single acquisition (positive units) or disposal (negative units) of a holding.
+type holdingEvent struct {
+	Date  time.Time
+	Units decimal.Decimal
+	Value decimal.Decimal // always positive: cost for acquisitions, proceeds for disposals.
+	Fee   bool            // Units removed to pay a fee. This isn't a disposal, and the cost stays with the remaining units.
+}
+
+type MatchingFunction func(events []holdingEvent) []Disposal
@@ -0,0 +1,149 @@
+package goals
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/goldsproutapp/goldsprout-backend/calculations/returns"
+	"github.com/goldsproutapp/goldsprout-backend/calculations/split"
+	"github.com/goldsproutapp/goldsprout-backend/constants"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/shopspring/decimal"
+)
+
+var hundred = decimal.NewFromInt(100)
+
+// Snapshots of the holdings linked to the goal. userStocks should contain the goal's linked holdings.
+func GoalSnapshots(goal models.Goal, userStocks []models.UserStock, snapshots []models.StockSnapshot) []models.StockSnapshot {
+	if len(goal.Accounts) == 0 && len(goal.Holdings) == 0 && len(goal.Tags) == 0 {
+		return snapshots
+	}
+	holdingKeys := map[string]bool{}
+	for _, userStock := range userStocks {
+		if slices.Contains(goal.Holdings, userStock.ID) {
+			holdingKeys[fmt.Sprintf("%v:%v", userStock.AccountID, userStock.StockID)] = true
+		}
+	}
+	out := []models.StockSnapshot{}
+	for _, snapshot := range snapshots {
+		if slices.Contains(goal.Accounts, snapshot.AccountID) || holdingKeys[snapshot.Key()] ||
+			slices.ContainsFunc(snapshot.Tags, func(tag string) bool { return slices.Contains(goal.Tags, tag) }) {
+			out = append(out, snapshot)
+		}
+	}
+	return out
+}
+
+func monthsBetween(from time.Time, to time.Time) int {
+	months := (to.Year()-from.Year())*12 + int(to.Month()-from.Month())
+	if to.Day() < from.Day() {
+		months--
+	}
+	if months < 0 {
+		return 0
+	}
+	return months
+}
+
+// Average net amount paid in per month, measured by the change in total cost.
+func averageMonthlyContribution(snapshots []models.StockSnapshot) decimal.Decimal {
+	if len(snapshots) == 0 {
+		return decimal.NewFromInt(0)
+	}
+	sorted := make([]models.StockSnapshot, len(snapshots))
+	copy(sorted, snapshots)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Date.Before(sorted[j].Date)
+	})
+	first := sorted[0].Date
+	initialCost := decimal.NewFromInt(0)
+	costs := map[string]decimal.Decimal{}
+	for _, snapshot := range sorted {
+		if snapshot.Date.Equal(first) {
+			initialCost = initialCost.Add(snapshot.Cost)
+		}
+		costs[snapshot.Key()] = snapshot.Cost
+	}
+	months := monthsBetween(first, sorted[len(sorted)-1].Date)
+	if months == 0 {
+		return decimal.NewFromInt(0)
+	}
+	finalCost := decimal.NewFromInt(0)
+	for _, cost := range costs {
+		finalCost = finalCost.Add(cost)
+	}
+	return finalCost.Sub(initialCost).Div(decimal.NewFromInt(int64(months)))
+}
+
+// decimal.NewFromFloat panics on infinite or NaN values, so those are capped at the largest float.
+func boundedDecimal(f float64) decimal.Decimal {
+	switch {
+	case math.IsNaN(f):
+		return decimal.NewFromInt(0)
+	case math.IsInf(f, 1):
+		return decimal.NewFromFloat(math.MaxFloat64)
+	case math.IsInf(f, -1):
+		return decimal.NewFromFloat(-math.MaxFloat64)
+	}
+	return decimal.NewFromFloat(f)
+}
+
+// Future value of the current amount plus a fixed monthly contribution.
+func project(current decimal.Decimal, contribution decimal.Decimal, monthlyRate float64, months int) decimal.Decimal {
+	if monthlyRate == 0 || math.IsNaN(monthlyRate) {
+		return current.Add(contribution.Mul(decimal.NewFromInt(int64(months))))
+	}
+	compound := boundedDecimal(math.Pow(1+monthlyRate, float64(months)))
+	annuity := compound.Sub(decimal.NewFromInt(1)).Div(boundedDecimal(monthlyRate))
+	return current.Mul(compound).Add(contribution.Mul(annuity))
+}
+
+// Monthly contribution needed for the current amount to reach the target.
+func requiredContribution(current decimal.Decimal, target decimal.Decimal, monthlyRate float64, months int) decimal.Decimal {
+	if months == 0 {
+		return decimal.Max(target.Sub(current), decimal.NewFromInt(0))
+	}
+	shortfall := target.Sub(project(current, decimal.NewFromInt(0), monthlyRate, months))
+	if !shortfall.IsPositive() {
+		return decimal.NewFromInt(0)
+	}
+	perUnit := project(decimal.NewFromInt(0), decimal.NewFromInt(1), monthlyRate, months)
+	return shortfall.Div(perUnit)
+}
+
+func CalculateGoalProgress(goal models.Goal, snapshots []models.StockSnapshot, now time.Time) GoalProgress {
+	current := decimal.NewFromInt(0)
+	for _, holding := range split.CurrentHoldings(snapshots) {
+		current = current.Add(holding.Value)
+	}
+	growth, period := returns.TimeWeightedGrowth(snapshots)
+	annual := returns.Annualise(growth, period)
+	annualFloat, _ := annual.Float64()
+	monthlyRate := math.Pow(1+annualFloat, 1.0/12) - 1
+	monthly := averageMonthlyContribution(snapshots)
+	months := monthsBetween(now, goal.TargetDate)
+
+	progress := GoalProgress{
+		Goal:                 goal,
+		CurrentValue:         current,
+		Remaining:            decimal.Max(goal.TargetAmount.Sub(current), decimal.NewFromInt(0)),
+		Progress:             current.Div(goal.TargetAmount).Mul(hundred).Truncate(2),
+		MonthsRemaining:      months,
+		AnnualGrowth:         annual.Mul(hundred).Truncate(2),
+		MonthlyContribution:  monthly.Truncate(2),
+		RequiredContribution: requiredContribution(current, goal.TargetAmount, monthlyRate, months).Truncate(2),
+		ProjectedValue:       project(current, decimal.Max(monthly, decimal.NewFromInt(0)), monthlyRate, months).Truncate(2),
+	}
+	switch {
+	case !progress.Remaining.IsPositive():
+		progress.Status = constants.GOAL_STATUS_ACHIEVED
+	case progress.ProjectedValue.GreaterThanOrEqual(goal.TargetAmount):
+		progress.Status = constants.GOAL_STATUS_ON_TRACK
+	default:
+		progress.Status = constants.GOAL_STATUS_OFF_TRACK
+	}
+	return progress
+}
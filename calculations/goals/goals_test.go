@@ -0,0 +1,40 @@
+package goals
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/shopspring/decimal"
+)
+
+func TestShortHistoryIsNotAnnualised(t *testing.T) {
+	date := time.Date(2024, time.January, 1, 9, 0, 0, 0, time.UTC)
+	snapshots := []models.StockSnapshot{
+		{AccountID: 1, StockID: 1, Date: date, Value: decimal.NewFromInt(1000), Cost: decimal.NewFromInt(1000)},
+		{AccountID: 1, StockID: 1, Date: date.Add(3 * time.Hour), Value: decimal.NewFromInt(1010),
+			ChangeSinceLast: decimal.NewFromInt(10), Cost: decimal.NewFromInt(1000)},
+	}
+	goal := models.Goal{TargetAmount: decimal.NewFromInt(100000), TargetDate: date.AddDate(30, 0, 0)}
+
+	progress := CalculateGoalProgress(goal, snapshots, date.Add(3*time.Hour))
+	if !progress.AnnualGrowth.IsZero() {
+		t.Errorf("annual growth: got %v, expected 0", progress.AnnualGrowth)
+	}
+	if !progress.ProjectedValue.Equal(decimal.NewFromInt(1010)) {
+		t.Errorf("projected value: got %v, expected 1010", progress.ProjectedValue)
+	}
+	if !progress.RequiredContribution.Equal(decimal.RequireFromString("274.97")) {
+		t.Errorf("required contribution: got %v, expected 274.97", progress.RequiredContribution)
+	}
+}
+
+func TestProjectWithExtremeRate(t *testing.T) {
+	value := project(decimal.NewFromInt(1000), decimal.NewFromInt(100), 1000, 360)
+	if !value.IsPositive() {
+		t.Errorf("projected value: got %v, expected a positive value", value)
+	}
+	if required := requiredContribution(decimal.NewFromInt(1000), decimal.NewFromInt(100000), 1000, 360); !required.IsZero() {
+		t.Errorf("required contribution: got %v, expected 0", required)
+	}
+}
@@ -0,0 +1,9 @@
+package goals
+
+import (
+	"github.com/goldsproutapp/goldsprout-backend/models"
+)
+
+func IsGoalValid(goal models.GoalRequest) bool {
+	return goal.TargetAmount.IsPositive() && goal.TargetDate > 0
+}
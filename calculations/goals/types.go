@@ -0,0 +1,23 @@
+package goals
+
+import (
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/shopspring/decimal"
+)
+
+type GoalProgress struct {
+	Goal            models.Goal     `json:"goal"`
+	CurrentValue    decimal.Decimal `json:"current_value"`
+	Remaining       decimal.Decimal `json:"remaining"`
+	Progress        decimal.Decimal `json:"progress"` // percentage of the target amount
+	MonthsRemaining int             `json:"months_remaining"`
+	// Historical annualised growth (time-weighted, as a percentage; zero with less than a year of history)
+	// and average monthly net contribution.
+	AnnualGrowth        decimal.Decimal `json:"annual_growth"`
+	MonthlyContribution decimal.Decimal `json:"monthly_contribution"`
+	// Monthly contribution needed to reach the target, assuming historical growth continues.
+	RequiredContribution decimal.Decimal `json:"required_contribution"`
+	// Value at the target date if historical growth and contributions continue.
+	ProjectedValue decimal.Decimal `json:"projected_value"`
+	Status         string          `json:"status"`
+}
@@ -0,0 +1,79 @@
+package networth
+
+import (
+	"time"
+
+	"github.com/goldsproutapp/goldsprout-backend/lib/processing"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/shopspring/decimal"
+)
+
+func investmentsAt(groups map[uint][]models.StockSnapshot, date time.Time) decimal.Decimal {
+	total := decimal.NewFromInt(0)
+	for _, snapshot := range processing.HoldingsAt(groups, date) {
+		total = total.Add(snapshot.Value)
+	}
+	return total
+}
+
+// Balances of assets and liabilities on the given date, carrying forward the last valuation.
+func assetsAt(assets []models.Asset, date time.Time) (decimal.Decimal, decimal.Decimal) {
+	assetTotal := decimal.NewFromInt(0)
+	liabilityTotal := decimal.NewFromInt(0)
+	for _, asset := range assets {
+		var current *models.AssetValuation
+		for i, valuation := range asset.Valuations {
+			if valuation.Date.After(date) {
+				break
+			}
+			current = &asset.Valuations[i]
+		}
+		if current == nil {
+			continue
+		}
+		if asset.IsLiability() {
+			liabilityTotal = liabilityTotal.Add(current.Value)
+		} else {
+			assetTotal = assetTotal.Add(current.Value)
+		}
+	}
+	return assetTotal, liabilityTotal
+}
+
+// Net worth at the end of each month from the earliest record, and at the current time.
+// Asset valuations should be sorted by date.
+func CalculateNetWorthHistory(snapshots []models.StockSnapshot, assets []models.Asset, now time.Time) []models.NetWorthPoint {
+	accountBatches := processing.GroupByAccount(snapshots)
+	var start time.Time
+	for _, snapshot := range snapshots {
+		if start.IsZero() || snapshot.Date.Before(start) {
+			start = snapshot.Date
+		}
+	}
+	for _, asset := range assets {
+		if len(asset.Valuations) > 0 && (start.IsZero() || asset.Valuations[0].Date.Before(start)) {
+			start = asset.Valuations[0].Date
+		}
+	}
+	points := []models.NetWorthPoint{}
+	if start.IsZero() {
+		return points
+	}
+	dates := []time.Time{}
+	for monthStart := time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, start.Location()).AddDate(0, 1, 0); monthStart.Before(now); monthStart = monthStart.AddDate(0, 1, 0) {
+		dates = append(dates, monthStart.Add(-time.Second))
+	}
+	dates = append(dates, now)
+	for _, date := range dates {
+		investments := investmentsAt(accountBatches, date)
+		assetTotal, liabilityTotal := assetsAt(assets, date)
+		points = append(points, models.NetWorthPoint{
+			Date:        date,
+			Investments: investments,
+			Assets:      assetTotal,
+			Liabilities: liabilityTotal,
+			NetWorth:    investments.Add(assetTotal).Add(liabilityTotal),
+		})
+	}
+	return points
+}
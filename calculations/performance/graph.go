@@ -3,16 +3,27 @@ package performance
 import (
 	"time"
 
+	"github.com/goldsproutapp/goldsprout-backend/lib/extraction/times"
 	"github.com/goldsproutapp/goldsprout-backend/lib/processing"
 	"github.com/goldsproutapp/goldsprout-backend/models"
 	"github.com/goldsproutapp/goldsprout-backend/util"
 	"github.com/shopspring/decimal"
 )
 
-func GeneratePerformanceGraphInfo(snapshots []models.StockSnapshot) PerformanceGraphInfo {
+func GeneratePerformanceGraphInfo(snapshots []models.StockSnapshot, fiscalYear times.YearStart) PerformanceGraphInfo {
 
-	snapshotMapMerged, yearStartMap := processing.CreateMergedSnapshotMap(snapshots)
+	snapshotMapMerged, yearStartMap := processing.CreateMergedSnapshotMap(snapshots, fiscalYear)
+	return summarisePerformance(snapshotMapMerged, yearStartMap)
+}
+
+// Generate graph info as a regular series at the given resolution, carrying holdings forward
+// between snapshots and revaluing them using prices where available.
+func GenerateRegularPerformanceGraphInfo(snapshots []models.StockSnapshot, prices []models.StockSnapshot, fiscalYear times.YearStart, resolution string) PerformanceGraphInfo {
+	snapshotMap := processing.CreateRegularSnapshotMap(snapshots, prices, resolution, time.Now())
+	return summarisePerformance(snapshotMap, processing.CreateYearStartMap(snapshots, fiscalYear))
+}
 
+func summarisePerformance(snapshotMapMerged map[time.Time][]models.StockSnapshot, yearStartMap map[string][]models.StockSnapshot) PerformanceGraphInfo {
 	valueOut := map[time.Time]decimal.Decimal{}
 	costOut := map[time.Time]decimal.Decimal{}
 	perfOut := map[time.Time]decimal.Decimal{}
@@ -38,7 +49,9 @@ func GeneratePerformanceGraphInfo(snapshots []models.StockSnapshot) PerformanceG
 			perfOut[time] = perfOut[time].Add(p)
 			counted[snapshot.Key()] = snapshot
 		}
-		perfOut[time] = perfOut[time].Div(valueOut[time]).Truncate(2)
+		if !valueOut[time].IsZero() {
+			perfOut[time] = perfOut[time].Div(valueOut[time]).Truncate(2)
+		}
 	}
 	totalGPP := decimal.NewFromInt(0)
 	totalGain := decimal.NewFromInt(0)
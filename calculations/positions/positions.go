@@ -0,0 +1,117 @@
+package positions
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/goldsproutapp/goldsprout-backend/calculations/reports"
+	"github.com/goldsproutapp/goldsprout-backend/constants"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+// Pool the holding at average cost, from the unit changes between its snapshots (which are in
+// date order), so that the pool always matches the recorded units. Income adds units at their
+// value, while fees remove units without changing the cost of those remaining. Where a snapshot
+// records a cost, it is taken as the cost of the pool.
+func applySnapshots(position *Position, snapshots []models.StockSnapshot) {
+	zero := decimal.NewFromInt(0)
+	hundred := decimal.NewFromInt(100)
+	units := zero
+	cost := zero
+	apply := func(unitChange decimal.Decimal, price decimal.Decimal, attribution uint, recordedCost decimal.Decimal) {
+		value := unitChange.Mul(price).Div(hundred)
+		if unitChange.IsPositive() {
+			invested := value
+			if recordedCost.GreaterThan(cost) {
+				invested = recordedCost.Sub(cost)
+			}
+			units = units.Add(unitChange)
+			cost = cost.Add(invested)
+			if attribution == constants.TransAttrBuySell {
+				position.TotalInvested = position.TotalInvested.Add(invested)
+			}
+		} else if unitChange.IsNegative() && units.IsPositive() {
+			sold := decimal.Min(unitChange.Neg(), units)
+			if attribution == constants.TransAttrBuySell {
+				removedCost := cost.Mul(sold).Div(units)
+				position.RealisedGain = position.RealisedGain.Add(value.Abs().Sub(removedCost))
+				cost = cost.Sub(removedCost)
+			}
+			units = units.Sub(sold)
+		}
+		if units.IsZero() {
+			cost = zero
+		} else if recordedCost.IsPositive() {
+			cost = recordedCost
+		}
+	}
+	for _, snapshot := range snapshots {
+		apply(snapshot.Units.Sub(units), snapshot.Price, snapshot.TransactionAttribution, snapshot.Cost)
+	}
+	if !position.CurrentlyHeld && units.IsPositive() && len(snapshots) > 0 {
+		// Sold without a zero-entry being recorded.
+		last := snapshots[len(snapshots)-1]
+		apply(units.Neg(), last.Price, constants.TransAttrBuySell, zero)
+	}
+	position.CostBasis = cost.Truncate(2)
+	if position.CurrentlyHeld && units.IsPositive() {
+		// Prices are in pence, so the average cost is too.
+		position.AverageCost = cost.Mul(hundred).Div(units).Truncate(4)
+		position.UnrealisedGain = position.Value.Sub(cost).Truncate(2)
+	}
+	position.RealisedGain = position.RealisedGain.Truncate(2)
+}
+
+// Summarise each of the given holdings. Snapshots should cover the full history of each holding.
+func CalculatePositions(db *gorm.DB, userStocks []models.UserStock, snapshots []models.StockSnapshot) []Position {
+	holdingReports := reports.HoldingReports(db, snapshots)
+	holdings := map[string][]models.StockSnapshot{}
+	for _, snapshot := range snapshots {
+		holdings[snapshot.Key()] = append(holdings[snapshot.Key()], snapshot)
+	}
+	for _, holding := range holdings {
+		sort.SliceStable(holding, func(i, j int) bool {
+			return holding[i].Date.Before(holding[j].Date)
+		})
+	}
+	zero := decimal.NewFromInt(0)
+	out := []Position{}
+	for _, userStock := range userStocks {
+		key := fmt.Sprintf("%v:%v", userStock.AccountID, userStock.StockID)
+		report, ok := holdingReports[key]
+		if !ok {
+			continue
+		}
+		last := holdings[key][len(holdings[key])-1]
+		position := Position{
+			UserStockID:   userStock.ID,
+			UserID:        userStock.UserID,
+			AccountID:     userStock.AccountID,
+			AccountName:   last.Account.Name,
+			StockID:       userStock.StockID,
+			StockName:     last.Stock.Name,
+			CurrentlyHeld: report.EndValue.IsPositive(),
+			Units:         zero,
+			Price:         last.Price,
+			Value:         report.EndValue,
+
+			TotalInvested:  zero,
+			CostBasis:      zero,
+			AverageCost:    zero,
+			UnrealisedGain: zero,
+			RealisedGain:   zero,
+
+			Income:       report.TotalIncome,
+			Fees:         report.TotalFeePaid,
+			ExpectedFees: report.ExpectedFees,
+		}
+		if position.CurrentlyHeld {
+			position.Units = last.Units
+		}
+		applySnapshots(&position, holdings[key])
+		out = append(out, position)
+	}
+	return out
+}
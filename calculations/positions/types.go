@@ -0,0 +1,27 @@
+package positions
+
+import "github.com/shopspring/decimal"
+
+type Position struct {
+	UserStockID   uint   `json:"user_stock_id"`
+	UserID        uint   `json:"user_id"`
+	AccountID     uint   `json:"account_id"`
+	AccountName   string `json:"account_name"`
+	StockID       uint   `json:"stock_id"`
+	StockName     string `json:"stock_name"`
+	CurrentlyHeld bool   `json:"currently_held"`
+
+	Units decimal.Decimal `json:"units"`
+	Price decimal.Decimal `json:"price"`
+	Value decimal.Decimal `json:"value"`
+
+	TotalInvested  decimal.Decimal `json:"total_invested"` // sum of all purchases
+	CostBasis      decimal.Decimal `json:"cost_basis"`     // average cost of the units currently held
+	AverageCost    decimal.Decimal `json:"average_cost"`   // per unit, in the same units as price
+	UnrealisedGain decimal.Decimal `json:"unrealised_gain"`
+	RealisedGain   decimal.Decimal `json:"realised_gain"`
+
+	Income       decimal.Decimal `json:"income"`
+	Fees         decimal.Decimal `json:"fees"`          // recorded as income/fee transactions
+	ExpectedFees decimal.Decimal `json:"expected_fees"` // accrued from the stock and provider annual fees
+}
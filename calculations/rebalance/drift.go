@@ -0,0 +1,35 @@
+package rebalance
+
+import (
+	"github.com/goldsproutapp/goldsprout-backend/calculations/split"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/goldsproutapp/goldsprout-backend/util"
+	"github.com/shopspring/decimal"
+)
+
+type CategoryDrift struct {
+	Category         string
+	TargetPercentage decimal.Decimal
+	ActualPercentage decimal.Decimal
+	OutOfTolerance   bool
+}
+
+// Compare the current split of the target's dimension against each category's target.
+func CalculateDrift(target models.AllocationTarget, snapshots []models.StockSnapshot) []CategoryDrift {
+	actual := split.CalculateSplit(split.CategoriseSnapshots(split.CurrentHoldings(snapshots), target.Dimension))
+	categories := util.HashSetFrom(util.MapKeys(actual))
+	for category := range target.Targets {
+		categories.Add(category)
+	}
+	out := []CategoryDrift{}
+	for _, category := range categories.Items() {
+		drift := actual[category].Sub(target.Targets[category]).Abs()
+		out = append(out, CategoryDrift{
+			Category:         category,
+			TargetPercentage: target.Targets[category],
+			ActualPercentage: actual[category],
+			OutOfTolerance:   drift.GreaterThan(target.Tolerance),
+		})
+	}
+	return out
+}
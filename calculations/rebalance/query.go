@@ -0,0 +1,23 @@
+package rebalance
+
+import (
+	"slices"
+
+	"github.com/goldsproutapp/goldsprout-backend/lib/extraction"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/shopspring/decimal"
+)
+
+func IsTargetValid(target models.AllocationTargetRequest) bool {
+	if (target.Tolerance != nil && target.Tolerance.IsNegative()) || target.Dimension == "all" || !slices.Contains(extraction.AllTargets(), target.Dimension) {
+		return false
+	}
+	total := decimal.NewFromInt(0)
+	for _, percentage := range target.Targets {
+		if percentage.IsNegative() {
+			return false
+		}
+		total = total.Add(percentage)
+	}
+	return total.Equal(decimal.NewFromInt(100))
+}
@@ -0,0 +1,128 @@
+package rebalance
+
+import (
+	"sort"
+
+	"github.com/goldsproutapp/goldsprout-backend/calculations/split"
+	"github.com/goldsproutapp/goldsprout-backend/lib/extraction"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/goldsproutapp/goldsprout-backend/util"
+	"github.com/shopspring/decimal"
+)
+
+var hundred = decimal.NewFromInt(100)
+
+// Current value of each category of the target's dimension, and the contribution
+// of each holding to it.
+func categoriseHoldings(holdings []models.StockSnapshot, dimension string) (map[string]decimal.Decimal, map[string]map[string]decimal.Decimal) {
+	categoryValues := map[string]decimal.Decimal{}
+	holdingShares := map[string]map[string]decimal.Decimal{} // category -> holding key -> value
+	for _, holding := range holdings {
+		for _, category := range extraction.GetKeysFromSnapshot(holding, dimension) {
+			value := extraction.GetContributionForCategory(holding, dimension, category).Value
+			if !util.ContainsKey(holdingShares, category) {
+				holdingShares[category] = map[string]decimal.Decimal{}
+				categoryValues[category] = decimal.NewFromInt(0)
+			}
+			holdingShares[category][holding.Key()] = value
+			categoryValues[category] = categoryValues[category].Add(value)
+		}
+	}
+	return categoryValues, holdingShares
+}
+
+// Compare current holdings against the target and propose a trade for each holding.
+// Trades within a category are split in proportion to the existing holdings, which
+// is only approximate for holdings spanning several categories (eg. multi-asset funds).
+func CalculateRebalance(target models.AllocationTarget, snapshots []models.StockSnapshot, contribution decimal.Decimal, contributionsOnly bool) RebalanceResult {
+	holdings := split.CurrentHoldings(snapshots)
+	categoryValues, holdingShares := categoriseHoldings(holdings, target.Dimension)
+
+	total := decimal.NewFromInt(0)
+	for _, holding := range holdings {
+		total = total.Add(holding.Value)
+	}
+	newTotal := total.Add(contribution)
+
+	categories := util.HashSetFrom(util.MapKeys(categoryValues))
+	for category := range target.Targets {
+		categories.Add(category)
+	}
+	result := map[string]CategoryRebalance{}
+	changes := map[string]decimal.Decimal{}
+	positiveGaps := decimal.NewFromInt(0)
+	for _, category := range categories.Items() {
+		current := categoryValues[category]
+		targetPercentage := target.Targets[category]
+		targetValue := newTotal.Mul(targetPercentage).Div(hundred)
+		changes[category] = targetValue.Sub(current)
+		if changes[category].IsPositive() {
+			positiveGaps = positiveGaps.Add(changes[category])
+		}
+		currentPercentage := decimal.NewFromInt(0)
+		if !total.IsZero() {
+			currentPercentage = current.Div(total).Mul(hundred)
+		}
+		result[category] = CategoryRebalance{
+			CurrentValue:      current.Round(2),
+			CurrentPercentage: currentPercentage.Truncate(2),
+			TargetValue:       targetValue.Round(2),
+			TargetPercentage:  targetPercentage,
+		}
+	}
+	if contributionsOnly {
+		// Only the contribution is available, so spread it across the underweight categories.
+		for category, change := range changes {
+			if !change.IsPositive() || positiveGaps.IsZero() {
+				changes[category] = decimal.NewFromInt(0)
+			} else {
+				changes[category] = change.Mul(contribution).Div(positiveGaps)
+			}
+		}
+	}
+
+	tradeAmounts := map[string]decimal.Decimal{}
+	unallocated := map[string]decimal.Decimal{}
+	for category, change := range changes {
+		r := result[category]
+		r.Change = change.Round(2)
+		result[category] = r
+		categoryValue := categoryValues[category]
+		if categoryValue.IsZero() {
+			if change.IsPositive() {
+				unallocated[category] = change.Round(2)
+			}
+			continue
+		}
+		for key, value := range holdingShares[category] {
+			tradeAmounts[key] = tradeAmounts[key].Add(change.Mul(value).Div(categoryValue))
+		}
+	}
+
+	trades := []HoldingTrade{}
+	for _, holding := range holdings {
+		amount := tradeAmounts[holding.Key()].Round(2)
+		if amount.IsZero() {
+			continue
+		}
+		trades = append(trades, HoldingTrade{
+			UserID:       holding.UserID,
+			AccountID:    holding.AccountID,
+			StockID:      holding.StockID,
+			StockName:    holding.Stock.Name,
+			CurrentValue: holding.Value,
+			Amount:       amount,
+		})
+	}
+	sort.Slice(trades, func(i, j int) bool {
+		return trades[i].Amount.GreaterThan(trades[j].Amount)
+	})
+	return RebalanceResult{
+		Target:       target,
+		TotalValue:   total,
+		Contribution: contribution,
+		Categories:   result,
+		Trades:       trades,
+		Unallocated:  unallocated,
+	}
+}
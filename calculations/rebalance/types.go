@@ -0,0 +1,33 @@
+package rebalance
+
+import (
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/shopspring/decimal"
+)
+
+type CategoryRebalance struct {
+	CurrentValue      decimal.Decimal `json:"current_value"`
+	CurrentPercentage decimal.Decimal `json:"current_percentage"`
+	TargetValue       decimal.Decimal `json:"target_value"`
+	TargetPercentage  decimal.Decimal `json:"target_percentage"`
+	Change            decimal.Decimal `json:"change"`
+}
+
+type HoldingTrade struct {
+	UserID       uint            `json:"user_id"`
+	AccountID    uint            `json:"account_id"`
+	StockID      uint            `json:"stock_id"`
+	StockName    string          `json:"stock_name"`
+	CurrentValue decimal.Decimal `json:"current_value"`
+	Amount       decimal.Decimal `json:"amount"` // positive to buy, negative to sell
+}
+
+type RebalanceResult struct {
+	Target       models.AllocationTarget      `json:"target"`
+	TotalValue   decimal.Decimal              `json:"total_value"`
+	Contribution decimal.Decimal              `json:"contribution"`
+	Categories   map[string]CategoryRebalance `json:"categories"`
+	Trades       []HoldingTrade               `json:"trades"`
+	// Amounts which should be invested in a category that has no existing holdings.
+	Unallocated map[string]decimal.Decimal `json:"unallocated"`
+}
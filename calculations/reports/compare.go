@@ -0,0 +1,140 @@
+package reports
+
+import (
+	"time"
+
+	"github.com/goldsproutapp/goldsprout-backend/calculations/split"
+	"github.com/goldsproutapp/goldsprout-backend/database"
+	"github.com/goldsproutapp/goldsprout-backend/lib/extraction/times"
+	"github.com/goldsproutapp/goldsprout-backend/lib/processing"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+const (
+	COMPARE_PREVIOUS  = "previous"
+	COMPARE_LAST_YEAR = "last_year"
+)
+
+var comparedFields = map[string]func(Report) decimal.Decimal{
+	"start_value":    func(r Report) decimal.Decimal { return r.StartValue },
+	"end_value":      func(r Report) decimal.Decimal { return r.EndValue },
+	"gross_change":   func(r Report) decimal.Decimal { return r.GrossChange },
+	"purchase_total": func(r Report) decimal.Decimal { return r.PurchaseTotal },
+	"sell_total":     func(r Report) decimal.Decimal { return r.SellTotal },
+	"net_cashflow":   func(r Report) decimal.Decimal { return r.NetCashflow },
+	"total_gain":     func(r Report) decimal.Decimal { return r.TotalGain },
+	"total_income":   func(r Report) decimal.Decimal { return r.TotalIncome },
+	"total_fee_paid": func(r Report) decimal.Decimal { return r.TotalFeePaid },
+	"expected_fees":  func(r Report) decimal.Decimal { return r.ExpectedFees },
+}
+
+func compareReports(current Report, previous Report) map[string]ReportDelta {
+	out := map[string]ReportDelta{}
+	for name, field := range comparedFields {
+		delta := ReportDelta{
+			Current:  field(current),
+			Previous: field(previous),
+		}
+		delta.Change = delta.Current.Sub(delta.Previous)
+		if !delta.Previous.IsZero() {
+			pct := delta.Change.Div(delta.Previous.Abs()).Mul(decimal.NewFromInt(100)).Truncate(2)
+			delta.Percentage = &pct
+		}
+		out[name] = delta
+	}
+	return out
+}
+
+func formatPeriod(period string, fiscalYear times.YearStart, date time.Time) string {
+	return times.ReportExtractionSet(fiscalYear)[period](models.StockSnapshot{Date: date})
+}
+
+// The label of the period to compare against, given any date within the current period.
+func comparisonPeriod(period string, against string, fiscalYear times.YearStart, date time.Time) string {
+	if against == COMPARE_LAST_YEAR {
+		return formatPeriod(period, fiscalYear, date.AddDate(-1, 0, 0))
+	}
+	return formatPeriod(period, fiscalYear, GetPreviousTimePeriod(period, fiscalYear, date).Add(-time.Second))
+}
+
+func snapshotsInPeriod(period string, fiscalYear times.YearStart, label string, snapshots []models.StockSnapshot) []models.StockSnapshot {
+	out := []models.StockSnapshot{}
+	for _, snapshot := range snapshots {
+		if formatPeriod(period, fiscalYear, snapshot.Date) == label {
+			out = append(out, snapshot)
+		}
+	}
+	return out
+}
+
+// As AggregateSnapshots, but taking the holdings before the start from the given history
+// rather than the database, so that they can be restricted to a category.
+func aggregateFromHistory(start time.Time, snapshots []models.StockSnapshot, history []models.StockSnapshot) AggregatedSnapshotsMap {
+	aggregated := AggregatedSnapshotsMap{
+		Snapshots:       map[string][]models.StockSnapshot{},
+		AccountPrevious: map[uint]map[uint]models.StockSnapshot{},
+		AccountLast:     map[uint]time.Time{},
+	}
+	for _, snapshot := range snapshots {
+		aggregated.Snapshots[snapshot.Key()] = append(aggregated.Snapshots[snapshot.Key()], snapshot)
+		if last, ok := aggregated.AccountLast[snapshot.AccountID]; !ok || last.Before(snapshot.Date) {
+			aggregated.AccountLast[snapshot.AccountID] = snapshot.Date
+		}
+		aggregated.AccountPrevious[snapshot.AccountID] = map[uint]models.StockSnapshot{}
+	}
+	for _, snapshot := range processing.HoldingsAt(processing.GroupByAccount(history), start.Add(-time.Nanosecond)) {
+		if prev, ok := aggregated.AccountPrevious[snapshot.AccountID]; ok {
+			prev[snapshot.StockID] = snapshot
+		}
+	}
+	return aggregated
+}
+
+func categoryReport(period string, fiscalYear times.YearStart, label string, snapshots []models.StockSnapshot) Report {
+	inPeriod := snapshotsInPeriod(period, fiscalYear, label, snapshots)
+	if len(inPeriod) == 0 {
+		return emptyReport()
+	}
+	start := GetPreviousTimePeriod(period, fiscalYear, inPeriod[0].Date)
+	return generateReport(aggregateFromHistory(start, inPeriod, snapshots))
+}
+
+// Compare the report for one period against another. If the current period isn't given, the latest is used,
+// and if the previous period isn't given, it is chosen according to query.Against.
+// Returns false if there are no snapshots in the current period.
+func CompareReports(db *gorm.DB, filter database.StockFilter, query models.ReportComparisonRequestQuery, fiscalYear times.YearStart, snapshots []models.StockSnapshot) (ReportComparison, bool) {
+	periods, reportMap := CalculateReport(db, filter, query.ReportRequestQuery, fiscalYear, snapshots)
+	current := query.Current
+	if current == "" && len(periods) > 1 {
+		current = periods[len(periods)-2] // the last entry is the total
+	}
+	currentSnapshots := snapshotsInPeriod(query.Period, fiscalYear, current, snapshots)
+	if len(currentSnapshots) == 0 {
+		return ReportComparison{}, false
+	}
+	previous := query.Previous
+	if previous == "" {
+		previous = comparisonPeriod(query.Period, query.Against, fiscalYear, currentSnapshots[0].Date)
+	}
+	previousReport, ok := reportMap[previous]
+	if !ok {
+		previousReport = emptyReport()
+	}
+	comparison := ReportComparison{
+		Current:  current,
+		Previous: previous,
+		Deltas:   compareReports(reportMap[current], previousReport),
+	}
+	if query.By != "" {
+		comparison.Categories = map[string]map[string]ReportDelta{}
+		for category, categorySnapshots := range split.CategoriseSnapshots(snapshots, query.By) {
+			comparison.Categories[category] = compareReports(
+				categoryReport(query.Period, fiscalYear, current, categorySnapshots),
+				categoryReport(query.Period, fiscalYear, previous, categorySnapshots),
+			)
+		}
+	}
+	return comparison, true
+}
@@ -9,5 +9,11 @@ import (
 )
 
 func IsReportQueryValid(query models.ReportRequestQuery) bool {
-	return slices.Contains(extraction.TimeKeys(times.ReportExtractionSet()), query.Period)
+	return slices.Contains(extraction.TimeKeys(times.ReportExtractionSet(times.CalendarYear)), query.Period)
+}
+
+func IsReportComparisonQueryValid(query models.ReportComparisonRequestQuery) bool {
+	return IsReportQueryValid(query.ReportRequestQuery) &&
+		slices.Contains([]string{"", COMPARE_PREVIOUS, COMPARE_LAST_YEAR}, query.Against) &&
+		(query.By == "" || slices.Contains(extraction.AllTargets(), query.By))
 }
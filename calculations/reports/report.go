@@ -14,11 +14,11 @@ import (
 	"gorm.io/gorm"
 )
 
-func SplitSnapshots(timePeriodKey string, snapshots []models.StockSnapshot) (map[string][]models.StockSnapshot, []string) {
+func SplitSnapshots(timePeriodKey string, fiscalYear times.YearStart, snapshots []models.StockSnapshot) (map[string][]models.StockSnapshot, []string) {
 	splitSnapshotMap := map[string][]models.StockSnapshot{}
 	timePeriodMap := map[string]time.Time{}
 	for _, snapshot := range snapshots {
-		period := extraction.ExtractTimeFromSnapshot(times.ReportExtractionSet(), timePeriodKey, snapshot)
+		period := extraction.ExtractTimeFromSnapshot(times.ReportExtractionSet(fiscalYear), timePeriodKey, snapshot)
 		if !util.ContainsKey(splitSnapshotMap, period) {
 			splitSnapshotMap[period] = []models.StockSnapshot{}
 		}
@@ -142,9 +142,9 @@ func updateReportForHolding(aggregated AggregatedSnapshotsMap, key string, repor
 	report.Transactions = append(report.Transactions, transactions...)
 }
 
-func generateReport(aggregated AggregatedSnapshotsMap) Report {
+func emptyReport() Report {
 	zero := decimal.NewFromInt(0)
-	report := Report{
+	return Report{
 		StartValue:  zero,
 		EndValue:    zero,
 		GrossChange: zero,
@@ -162,6 +162,10 @@ func generateReport(aggregated AggregatedSnapshotsMap) Report {
 		Transactions:  []ReportTransaction{},
 		SnapshotCount: 0,
 	}
+}
+
+func generateReport(aggregated AggregatedSnapshotsMap) Report {
+	report := emptyReport()
 	for _, s := range aggregated.AccountPrevious {
 		for _, snapshot := range s {
 			report.StartValue = report.StartValue.Add(snapshot.Value)
@@ -200,8 +204,51 @@ func generateReport(aggregated AggregatedSnapshotsMap) Report {
 	return report
 }
 
-func CalculateReport(db *gorm.DB, filter database.StockFilter, query models.ReportRequestQuery, snapshots []models.StockSnapshot) ([]string, map[string]Report) {
-	split, times := SplitSnapshots(query.Period, snapshots)
+// All transactions inferred from the full snapshot history, in date order.
+func InferTransactions(db *gorm.DB, snapshots []models.StockSnapshot) []ReportTransaction {
+	if len(snapshots) == 0 {
+		return []ReportTransaction{}
+	}
+	start := snapshots[0].Date
+	for _, snapshot := range snapshots {
+		if snapshot.Date.Before(start) {
+			start = snapshot.Date
+		}
+	}
+	transactions := generateReport(AggregateSnapshots(db, start, snapshots)).Transactions
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return transactions[i].Date.Before(transactions[j].Date)
+	})
+	return transactions
+}
+
+// A report for each holding (by StockSnapshot.Key()) over its full history, with transactions in date order.
+func HoldingReports(db *gorm.DB, snapshots []models.StockSnapshot) map[string]Report {
+	out := map[string]Report{}
+	if len(snapshots) == 0 {
+		return out
+	}
+	start := snapshots[0].Date
+	for _, snapshot := range snapshots {
+		if snapshot.Date.Before(start) {
+			start = snapshot.Date
+		}
+	}
+	aggregated := AggregateSnapshots(db, start, snapshots)
+	for key, holdingSnapshots := range aggregated.Snapshots {
+		report := emptyReport()
+		report.SnapshotCount = len(holdingSnapshots)
+		updateReportForHolding(aggregated, key, &report)
+		sort.SliceStable(report.Transactions, func(i, j int) bool {
+			return report.Transactions[i].Date.Before(report.Transactions[j].Date)
+		})
+		out[key] = report
+	}
+	return out
+}
+
+func CalculateReport(db *gorm.DB, filter database.StockFilter, query models.ReportRequestQuery, fiscalYear times.YearStart, snapshots []models.StockSnapshot) ([]string, map[string]Report) {
+	split, times := SplitSnapshots(query.Period, fiscalYear, snapshots)
 	reportMap := map[string]Report{}
 	lowestDate := filter.LowerDate
 	if len(times) > 0 && len(split[times[0]]) > 0 {
@@ -216,7 +263,7 @@ func CalculateReport(db *gorm.DB, filter database.StockFilter, query models.Repo
 		}
 		t := lowestDate
 		if p != "Total" {
-			t = GetPreviousTimePeriod(query.Period, s[0].Date)
+			t = GetPreviousTimePeriod(query.Period, fiscalYear, s[0].Date)
 		}
 		aggregated := AggregateSnapshots(db, t, s)
 		report := generateReport(aggregated)
@@ -0,0 +1,114 @@
+package reports
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/goldsproutapp/goldsprout-backend/calculations/split"
+	"github.com/goldsproutapp/goldsprout-backend/constants"
+	"github.com/goldsproutapp/goldsprout-backend/lib/extraction/times"
+	"github.com/goldsproutapp/goldsprout-backend/lib/processing"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+)
+
+// The start (inclusive) and end (exclusive) of the statement period containing date.
+func StatementPeriod(period string, fiscalYear times.YearStart, date time.Time) (time.Time, time.Time) {
+	switch period {
+	case constants.STATEMENT_PERIOD_QUARTERLY:
+		start := times.QuarterStart(date)
+		return start, start.AddDate(0, 3, 0)
+	case constants.STATEMENT_PERIOD_ANNUAL:
+		start := fiscalYear.For(date)
+		return start, start.AddDate(1, 0, 0)
+	default:
+		start := times.MonthStart(date)
+		return start, start.AddDate(0, 1, 0)
+	}
+}
+
+// The most recent statement period to have ended before now.
+func LastCompletePeriod(period string, fiscalYear times.YearStart, now time.Time) (time.Time, time.Time) {
+	start, _ := StatementPeriod(period, fiscalYear, now)
+	return StatementPeriod(period, fiscalYear, start.Add(-time.Second))
+}
+
+// Build the statement for the period containing date. snapshots should contain the full
+// history up to (at least) the end of the period, so that opening holdings can be found.
+func BuildStatement(title string, period string, fiscalYear times.YearStart, date time.Time, snapshots []models.StockSnapshot) Statement {
+	start, end := StatementPeriod(period, fiscalYear, date)
+	history := []models.StockSnapshot{}
+	inPeriod := []models.StockSnapshot{}
+	names := map[string]models.StockSnapshot{}
+	for _, snapshot := range snapshots {
+		if !snapshot.Date.Before(end) {
+			continue
+		}
+		history = append(history, snapshot)
+		names[snapshot.Key()] = snapshot
+		if !snapshot.Date.Before(start) {
+			inPeriod = append(inPeriod, snapshot)
+		}
+	}
+	report := emptyReport()
+	if len(inPeriod) > 0 {
+		report = generateReport(aggregateFromHistory(start, inPeriod, history))
+	}
+	// Accounts without any snapshots in the period are carried through at their opening value.
+	active := map[uint]bool{}
+	for _, snapshot := range inPeriod {
+		active[snapshot.AccountID] = true
+	}
+	for _, snapshot := range processing.HoldingsAt(processing.GroupByAccount(history), start.Add(-time.Nanosecond)) {
+		if !active[snapshot.AccountID] {
+			report.StartValue = report.StartValue.Add(snapshot.Value)
+			report.EndValue = report.EndValue.Add(snapshot.Value)
+		}
+	}
+	sort.SliceStable(report.Transactions, func(i, j int) bool {
+		return report.Transactions[i].Date.Before(report.Transactions[j].Date)
+	})
+
+	transactions := make([]StatementTransaction, len(report.Transactions))
+	for i, transaction := range report.Transactions {
+		named := names[fmt.Sprintf("%v:%v", transaction.AccountID, transaction.StockID)]
+		transactions[i] = StatementTransaction{
+			ReportTransaction: transaction,
+			Account:           named.Account.Name,
+			Stock:             named.Stock.Name,
+		}
+	}
+
+	current := processing.HoldingsAt(processing.GroupByAccount(history), end.Add(-time.Nanosecond))
+	holdings := []StatementHolding{}
+	for _, snapshot := range current {
+		if snapshot.Value.IsZero() {
+			continue
+		}
+		holdings = append(holdings, StatementHolding{
+			Account: snapshot.Account.Name,
+			Stock:   snapshot.Stock.Name,
+			Units:   snapshot.Units,
+			Price:   snapshot.Price,
+			Value:   snapshot.Value,
+		})
+	}
+	sort.Slice(holdings, func(i, j int) bool {
+		if holdings[i].Account != holdings[j].Account {
+			return holdings[i].Account < holdings[j].Account
+		}
+		return holdings[i].Stock < holdings[j].Stock
+	})
+
+	return Statement{
+		Title:        title,
+		Period:       period,
+		Label:        formatPeriod(period, fiscalYear, start),
+		Start:        start,
+		End:          end,
+		Report:       report,
+		Allocation:   split.CalculateSplit(split.CategoriseSnapshots(current, "class")),
+		Holdings:     holdings,
+		Transactions: transactions,
+	}
+}
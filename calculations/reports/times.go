@@ -1,16 +1,24 @@
 package reports
 
-import "time"
+import (
+	"time"
 
-var prevPeriod = map[string]func(time.Time) time.Time{
-	"monthly": func(t time.Time) time.Time {
-		return t.AddDate(0, 0, -t.Day())
-	},
-	"annual": func(t time.Time) time.Time {
-		return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
-	},
+	"github.com/goldsproutapp/goldsprout-backend/lib/extraction/times"
+)
+
+func prevPeriod(fiscalYear times.YearStart) map[string]func(time.Time) time.Time {
+	return map[string]func(time.Time) time.Time{
+		"monthly": func(t time.Time) time.Time {
+			return t.AddDate(0, 0, -t.Day())
+		},
+		// Other periods open with the last snapshot before their start.
+		"annual":    fiscalYear.For,
+		"quarterly": times.QuarterStart,
+		"weekly":    times.WeekStart,
+		"tax_year":  times.TaxYearStart,
+	}
 }
 
-func GetPreviousTimePeriod(timeType string, period time.Time) time.Time {
-	return prevPeriod[timeType](period)
+func GetPreviousTimePeriod(timeType string, fiscalYear times.YearStart, period time.Time) time.Time {
+	return prevPeriod(fiscalYear)[timeType](period)
 }
@@ -0,0 +1,56 @@
+package reports
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goldsproutapp/goldsprout-backend/lib/extraction/times"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/shopspring/decimal"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// A snapshot on the last day of the previous period is the opening balance.
+func TestOpeningSnapshotOnBoundary(t *testing.T) {
+	cases := []struct {
+		period   string
+		boundary time.Time
+		inPeriod time.Time
+	}{
+		{"quarterly", date(2024, time.March, 31), date(2024, time.April, 15)},
+		{"weekly", date(2024, time.April, 7), date(2024, time.April, 10)},
+		{"tax_year", date(2024, time.April, 5), date(2024, time.May, 1)},
+		{"annual", date(2024, time.April, 5), date(2024, time.May, 1)},
+	}
+	for _, c := range cases {
+		t.Run(c.period, func(t *testing.T) {
+			snapshots := []models.StockSnapshot{
+				{AccountID: 1, StockID: 1, Date: c.boundary, Value: decimal.NewFromInt(1000), Units: decimal.NewFromInt(10)},
+				{AccountID: 1, StockID: 1, Date: c.inPeriod, Value: decimal.NewFromInt(1100), Units: decimal.NewFromInt(10),
+					ChangeSinceLast: decimal.NewFromInt(100)},
+			}
+			label := formatPeriod(c.period, times.TaxYear, c.inPeriod)
+			report := categoryReport(c.period, times.TaxYear, label, snapshots)
+			if !report.StartValue.Equal(decimal.NewFromInt(1000)) {
+				t.Errorf("start value: got %v, expected 1000", report.StartValue)
+			}
+			if !report.EndValue.Equal(decimal.NewFromInt(1100)) {
+				t.Errorf("end value: got %v, expected 1100", report.EndValue)
+			}
+		})
+	}
+}
+
+// With the fiscal year starting on the tax year, annual and tax-year reports open at the same point.
+func TestAnnualMatchesTaxYear(t *testing.T) {
+	for _, d := range []time.Time{date(2024, time.April, 5), date(2024, time.April, 6), date(2025, time.January, 1)} {
+		annual := GetPreviousTimePeriod("annual", times.TaxYear, d)
+		taxYear := GetPreviousTimePeriod("tax_year", times.TaxYear, d)
+		if !annual.Equal(taxYear) {
+			t.Errorf("%v: annual cutoff %v, tax year cutoff %v", d, annual, taxYear)
+		}
+	}
+}
@@ -41,8 +41,48 @@ type Report struct {
 	SnapshotCount int             `json:"snapshot_count"`
 }
 
+type ReportDelta struct {
+	Current    decimal.Decimal  `json:"current"`
+	Previous   decimal.Decimal  `json:"previous"`
+	Change     decimal.Decimal  `json:"change"`
+	Percentage *decimal.Decimal `json:"percentage,omitempty"` // omitted when the previous value is zero
+}
+
+type ReportComparison struct {
+	Current    string                            `json:"current"`
+	Previous   string                            `json:"previous"`
+	Deltas     map[string]ReportDelta            `json:"deltas"`
+	Categories map[string]map[string]ReportDelta `json:"categories,omitempty"`
+}
+
 type AggregatedSnapshotsMap struct {
 	Snapshots       map[string][]models.StockSnapshot      // StockSnapshot.key() -> []StockSnapshot
 	AccountPrevious map[uint]map[uint]models.StockSnapshot // AccountID -> StockID -> []StockSnapshot (penultimate snapshot list for account)
 	AccountLast     map[uint]time.Time                     // AccountID -> Date (latest snapshot date for account)
 }
+
+type StatementHolding struct {
+	Account string          `json:"account"`
+	Stock   string          `json:"stock"`
+	Units   decimal.Decimal `json:"units"`
+	Price   decimal.Decimal `json:"price"`
+	Value   decimal.Decimal `json:"value"`
+}
+
+type StatementTransaction struct {
+	ReportTransaction
+	Account string `json:"account"`
+	Stock   string `json:"stock"`
+}
+
+type Statement struct {
+	Title        string                     `json:"title"`
+	Period       string                     `json:"period"`
+	Label        string                     `json:"label"`
+	Start        time.Time                  `json:"start"`
+	End          time.Time                  `json:"end"`
+	Report       Report                     `json:"report"`
+	Allocation   map[string]decimal.Decimal `json:"allocation"` // class -> percentage, at the end of the period
+	Holdings     []StatementHolding         `json:"holdings"`
+	Transactions []StatementTransaction     `json:"transactions"`
+}
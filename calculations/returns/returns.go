@@ -0,0 +1,70 @@
+package returns
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/shopspring/decimal"
+)
+
+const DAYS_PER_YEAR = 365.25
+
+// Shorter histories aren't annualised, as extrapolating them exaggerates (or overflows) the rate.
+const MIN_ANNUALISED_DAYS = 365
+
+// Chain the gain of each snapshot date against the value held beforehand, so that
+// contributions and withdrawals don't count as growth.
+// Returns the growth factor (eg. 1.1 for +10%) and the period it covers.
+func TimeWeightedGrowth(snapshots []models.StockSnapshot) (decimal.Decimal, time.Duration) {
+	one := decimal.NewFromInt(1)
+	if len(snapshots) == 0 {
+		return one, 0
+	}
+	sorted := make([]models.StockSnapshot, len(snapshots))
+	copy(sorted, snapshots)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Date.Before(sorted[j].Date)
+	})
+	growth := one
+	values := map[string]decimal.Decimal{}
+	for i := 0; i < len(sorted); {
+		j := i
+		base := decimal.NewFromInt(0)
+		for _, value := range values {
+			base = base.Add(value)
+		}
+		gain := decimal.NewFromInt(0)
+		for ; j < len(sorted) && sorted[j].Date.Equal(sorted[i].Date); j++ {
+			key := sorted[j].Key()
+			if _, ok := values[key]; ok {
+				gain = gain.Add(sorted[j].ChangeSinceLast)
+			}
+		}
+		if base.IsPositive() {
+			growth = growth.Mul(one.Add(gain.Div(base)))
+		}
+		for k := i; k < j; k++ {
+			values[sorted[k].Key()] = sorted[k].Value
+		}
+		i = j
+	}
+	return growth, sorted[len(sorted)-1].Date.Sub(sorted[0].Date)
+}
+
+// Convert a growth factor over the given period to an annual rate (eg. 0.05 for +5% p.a.).
+// Zero if the period is shorter than MIN_ANNUALISED_DAYS.
+func Annualise(growth decimal.Decimal, period time.Duration) decimal.Decimal {
+	days := period.Hours() / 24
+	if days < MIN_ANNUALISED_DAYS || !growth.IsPositive() {
+		return decimal.NewFromInt(0)
+	}
+	factor, _ := growth.Float64()
+	rate := math.Pow(factor, DAYS_PER_YEAR/days) - 1
+	if math.IsInf(rate, 0) || math.IsNaN(rate) {
+		// decimal.NewFromFloat panics on either.
+		return decimal.NewFromInt(0)
+	}
+	return decimal.NewFromFloat(rate)
+}
@@ -0,0 +1,12 @@
+package rolling
+
+import (
+	"slices"
+
+	"github.com/goldsproutapp/goldsprout-backend/lib/extraction"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+)
+
+func IsRollingQueryValid(q models.RollingReturnsRequestQuery) bool {
+	return slices.Contains(extraction.AllTargets(), q.By)
+}
@@ -0,0 +1,110 @@
+package rolling
+
+import (
+	"sort"
+	"time"
+
+	"github.com/goldsproutapp/goldsprout-backend/calculations/returns"
+	"github.com/goldsproutapp/goldsprout-backend/calculations/split"
+	"github.com/goldsproutapp/goldsprout-backend/lib/processing"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/shopspring/decimal"
+)
+
+type period struct {
+	start      func(time.Time) time.Time
+	annualised bool
+}
+
+var periods = map[string]period{
+	"1m": {start: func(t time.Time) time.Time { return t.AddDate(0, -1, 0) }},
+	"3m": {start: func(t time.Time) time.Time { return t.AddDate(0, -3, 0) }},
+	"1y": {start: func(t time.Time) time.Time { return t.AddDate(-1, 0, 0) }, annualised: true},
+	"3y": {start: func(t time.Time) time.Time { return t.AddDate(-3, 0, 0) }, annualised: true},
+	"5y": {start: func(t time.Time) time.Time { return t.AddDate(-5, 0, 0) }, annualised: true},
+}
+
+var hundred = decimal.NewFromInt(100)
+
+// Snapshots within the period, preceded by the holdings at its start (dated at the start)
+// so that growth is measured from the value held at that point.
+// The first change of each holding within the period is measured from its last snapshot before
+// the start, so only the share of it which falls within the period is kept, assuming steady growth;
+// the rest is added to the value held at the start.
+// Returns false if the history doesn't reach back to the start of the period.
+func periodSnapshots(snapshots []models.StockSnapshot, groups map[uint][]models.StockSnapshot, start time.Time) ([]models.StockSnapshot, bool) {
+	initial := processing.HoldingsAt(groups, start)
+	if len(initial) == 0 {
+		return nil, false
+	}
+	out := make([]models.StockSnapshot, 0, len(snapshots))
+	carried := map[string]int{}            // key -> index in out
+	initialDates := map[string]time.Time{} // key -> date of the carried-in snapshot
+	for _, snapshot := range initial {
+		carried[snapshot.Key()] = len(out)
+		initialDates[snapshot.Key()] = snapshot.Date
+		snapshot.Date = start
+		out = append(out, snapshot)
+	}
+	inPeriod := []models.StockSnapshot{}
+	for _, snapshot := range snapshots {
+		if snapshot.Date.After(start) {
+			inPeriod = append(inPeriod, snapshot)
+		}
+	}
+	sort.SliceStable(inPeriod, func(i, j int) bool {
+		return inPeriod[i].Date.Before(inPeriod[j].Date)
+	})
+	for _, snapshot := range inPeriod {
+		key := snapshot.Key()
+		if idx, ok := carried[key]; ok {
+			delete(carried, key)
+			interval := snapshot.Date.Sub(initialDates[key])
+			if interval > 0 {
+				outside := decimal.NewFromFloat(float64(start.Sub(initialDates[key])) / float64(interval))
+				before := snapshot.ChangeSinceLast.Mul(outside)
+				snapshot.ChangeSinceLast = snapshot.ChangeSinceLast.Sub(before)
+				out[idx].Value = out[idx].Value.Add(before)
+			}
+		}
+		out = append(out, snapshot)
+	}
+	return out, true
+}
+
+func calculatePeriodReturns(snapshots []models.StockSnapshot, asOf time.Time) map[string]PeriodReturn {
+	groups := processing.GroupByAccount(snapshots)
+	out := map[string]PeriodReturn{}
+	for name, p := range periods {
+		start := p.start(asOf)
+		inPeriod, ok := periodSnapshots(snapshots, groups, start)
+		if !ok {
+			continue
+		}
+		growth, _ := returns.TimeWeightedGrowth(inPeriod)
+		result := PeriodReturn{
+			Start:      start,
+			Cumulative: growth.Sub(decimal.NewFromInt(1)).Mul(hundred).Truncate(2),
+		}
+		if p.annualised {
+			annualised := returns.Annualise(growth, asOf.Sub(start)).Mul(hundred).Truncate(2)
+			result.Annualised = &annualised
+		}
+		out[name] = result
+	}
+	return out
+}
+
+// Trailing returns up to the most recent snapshot, for each category of the given property.
+func CalculateRollingReturns(snapshots []models.StockSnapshot, by string) RollingReturns {
+	result := RollingReturns{Returns: map[string]map[string]PeriodReturn{}}
+	for _, snapshot := range snapshots {
+		if snapshot.Date.After(result.AsOf) {
+			result.AsOf = snapshot.Date
+		}
+	}
+	for category, categorySnapshots := range split.CategoriseSnapshots(snapshots, by) {
+		result.Returns[category] = calculatePeriodReturns(categorySnapshots, result.AsOf)
+	}
+	return result
+}
@@ -0,0 +1,19 @@
+package rolling
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type PeriodReturn struct {
+	Start      time.Time        `json:"start"`
+	Cumulative decimal.Decimal  `json:"cumulative"`           // percentage
+	Annualised *decimal.Decimal `json:"annualised,omitempty"` // percentage, only for periods of a year or more
+}
+
+type RollingReturns struct {
+	AsOf time.Time `json:"as_of"`
+	// category -> period -> return. Periods without enough history are omitted.
+	Returns map[string]map[string]PeriodReturn `json:"returns"`
+}
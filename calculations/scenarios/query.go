@@ -0,0 +1,19 @@
+package scenarios
+
+import (
+	"slices"
+
+	"github.com/goldsproutapp/goldsprout-backend/lib/extraction"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/shopspring/decimal"
+)
+
+func AreShocksValid(shocks []models.ScenarioShock) bool {
+	for _, shock := range shocks {
+		if shock.Dimension == "all" || !slices.Contains(extraction.AllTargets(), shock.Dimension) ||
+			shock.Category == "" || shock.Change.LessThan(decimal.NewFromInt(-100)) {
+			return false
+		}
+	}
+	return len(shocks) > 0
+}
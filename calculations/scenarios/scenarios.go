@@ -0,0 +1,76 @@
+package scenarios
+
+import (
+	"slices"
+
+	"github.com/goldsproutapp/goldsprout-backend/calculations/split"
+	"github.com/goldsproutapp/goldsprout-backend/lib/extraction"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/shopspring/decimal"
+)
+
+var hundred = decimal.NewFromInt(100)
+
+func newImpact() Impact {
+	return Impact{
+		Before:     decimal.NewFromInt(0),
+		After:      decimal.NewFromInt(0),
+		Change:     decimal.NewFromInt(0),
+		Percentage: decimal.NewFromInt(0),
+	}
+}
+
+func (i Impact) add(before decimal.Decimal, change decimal.Decimal) Impact {
+	i.Before = i.Before.Add(before)
+	i.Change = i.Change.Add(change)
+	i.After = i.Before.Add(i.Change)
+	if !i.Before.IsZero() {
+		i.Percentage = i.Change.Div(i.Before).Mul(hundred).Truncate(2)
+	}
+	return i
+}
+
+// Change in the holding's value from the shocks. Only the part of the holding in each shocked
+// category is affected, so multi-asset funds are shocked in proportion to their composition.
+// Shocks which overlap (eg. a class and a region) are additive, but never take a holding below zero.
+func shockHolding(holding models.StockSnapshot, shocks []models.ScenarioShock) decimal.Decimal {
+	change := decimal.NewFromInt(0)
+	for _, shock := range shocks {
+		if !slices.Contains(extraction.GetKeysFromSnapshot(holding, shock.Dimension), shock.Category) {
+			continue
+		}
+		exposure := extraction.GetContributionForCategory(holding, shock.Dimension, shock.Category).Value
+		change = change.Add(exposure.Mul(shock.Change).Div(hundred))
+	}
+	return decimal.Max(change, holding.Value.Neg()).Truncate(2)
+}
+
+// Apply the shocks to the latest holdings in snapshots.
+func RunScenario(shocks []models.ScenarioShock, snapshots []models.StockSnapshot) ScenarioResult {
+	result := ScenarioResult{
+		Total:    newImpact(),
+		Accounts: map[uint]Impact{},
+		Users:    map[uint]Impact{},
+		Holdings: []HoldingImpact{},
+	}
+	for _, holding := range split.CurrentHoldings(snapshots) {
+		change := shockHolding(holding, shocks)
+		result.Total = result.Total.add(holding.Value, change)
+		if _, ok := result.Accounts[holding.AccountID]; !ok {
+			result.Accounts[holding.AccountID] = newImpact()
+		}
+		result.Accounts[holding.AccountID] = result.Accounts[holding.AccountID].add(holding.Value, change)
+		if _, ok := result.Users[holding.UserID]; !ok {
+			result.Users[holding.UserID] = newImpact()
+		}
+		result.Users[holding.UserID] = result.Users[holding.UserID].add(holding.Value, change)
+		result.Holdings = append(result.Holdings, HoldingImpact{
+			Impact:    newImpact().add(holding.Value, change),
+			UserID:    holding.UserID,
+			AccountID: holding.AccountID,
+			StockID:   holding.StockID,
+			StockName: holding.Stock.Name,
+		})
+	}
+	return result
+}
@@ -0,0 +1,25 @@
+package scenarios
+
+import "github.com/shopspring/decimal"
+
+type Impact struct {
+	Before     decimal.Decimal `json:"before"`
+	After      decimal.Decimal `json:"after"`
+	Change     decimal.Decimal `json:"change"`
+	Percentage decimal.Decimal `json:"percentage"`
+}
+
+type HoldingImpact struct {
+	Impact
+	UserID    uint   `json:"user_id"`
+	AccountID uint   `json:"account_id"`
+	StockID   uint   `json:"stock_id"`
+	StockName string `json:"stock_name"`
+}
+
+type ScenarioResult struct {
+	Total    Impact          `json:"total"`
+	Accounts map[uint]Impact `json:"accounts"`
+	Users    map[uint]Impact `json:"users"`
+	Holdings []HoldingImpact `json:"holdings"`
+}
@@ -0,0 +1,32 @@
+package simulation
+
+import (
+	"github.com/goldsproutapp/goldsprout-backend/constants"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/shopspring/decimal"
+)
+
+// Bounded so that simulated values stay finite.
+func isAssumptionValid(assumption models.ReturnAssumption) bool {
+	return assumption.Return > -100 && assumption.Return <= constants.MAX_SIMULATION_RATE &&
+		assumption.Volatility >= 0 && assumption.Volatility <= constants.MAX_SIMULATION_RATE
+}
+
+var maxAmount = decimal.NewFromInt(constants.MAX_SIMULATION_AMOUNT)
+
+func IsSimulationValid(query models.SimulationRequest) bool {
+	if query.CurrentAge <= 0 || query.EndAge <= query.CurrentAge || query.EndAge-query.CurrentAge > 100 ||
+		query.WithdrawalAge < query.CurrentAge ||
+		query.Paths < 0 || query.Paths > constants.MAX_SIMULATION_PATHS ||
+		query.MonthlyContribution.IsNegative() || query.AnnualWithdrawal.IsNegative() ||
+		query.MonthlyContribution.GreaterThan(maxAmount) || query.AnnualWithdrawal.GreaterThan(maxAmount) ||
+		!isAssumptionValid(query.DefaultAssumption) {
+		return false
+	}
+	for _, assumption := range query.Assumptions {
+		if !isAssumptionValid(assumption) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,147 @@
+package simulation
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/goldsproutapp/goldsprout-backend/calculations/split"
+	"github.com/goldsproutapp/goldsprout-backend/constants"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/shopspring/decimal"
+)
+
+var percentiles = []int{10, 25, 50, 75, 90}
+
+type classAssumption struct {
+	weight     float64
+	mean       float64
+	volatility float64
+}
+
+// Current value of each class across the latest holdings.
+func classValues(snapshots []models.StockSnapshot) (map[string]float64, float64) {
+	values := map[string]float64{}
+	total := 0.0
+	for class, holdings := range split.CategoriseSnapshots(split.CurrentHoldings(snapshots), "class") {
+		for _, holding := range holdings {
+			value, _ := holding.Value.Float64()
+			values[class] += value
+			total += value
+		}
+	}
+	return values, total
+}
+
+func percentile(sorted []float64, p int) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(math.Round(float64(p) / 100 * float64(len(sorted)-1)))
+	return sorted[i]
+}
+
+// Project the latest holdings forward a year at a time. Each class's return is drawn independently
+// from a normal distribution, and the portfolio is assumed to be rebalanced to its current class
+// allocation every year. The same seed always produces the same result.
+func Simulate(query models.SimulationRequest, snapshots []models.StockSnapshot) SimulationResult {
+	seed := query.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	paths := query.Paths
+	if paths == 0 {
+		paths = constants.DEFAULT_SIMULATION_PATHS
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	values, total := classValues(snapshots)
+	classes := make([]string, 0, len(values))
+	for class := range values {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes) // map iteration order must not affect the random sequence
+	assumptions := make([]classAssumption, len(classes))
+	allocation := map[string]decimal.Decimal{}
+	for i, class := range classes {
+		assumption, ok := query.Assumptions[class]
+		if !ok {
+			assumption = query.DefaultAssumption
+		}
+		assumptions[i] = classAssumption{
+			weight:     values[class] / total,
+			mean:       assumption.Return / 100,
+			volatility: assumption.Volatility / 100,
+		}
+		allocation[class] = decimal.NewFromFloat(assumptions[i].weight * 100).Truncate(2)
+	}
+	if len(classes) == 0 {
+		// Nothing is held yet, so contributions are invested according to the default assumption.
+		assumptions = []classAssumption{{weight: 1, mean: query.DefaultAssumption.Return / 100, volatility: query.DefaultAssumption.Volatility / 100}}
+	}
+	contribution, _ := query.MonthlyContribution.Mul(decimal.NewFromInt(12)).Float64()
+	withdrawal, _ := query.AnnualWithdrawal.Float64()
+
+	years := query.EndAge - query.CurrentAge
+	outcomes := make([][]float64, years)
+	depleted := make([]int, years)
+	for y := range outcomes {
+		outcomes[y] = make([]float64, paths)
+	}
+	for p := 0; p < paths; p++ {
+		value := total
+		ranOut := false
+		for y := 0; y < years; y++ {
+			growth := 0.0
+			for _, assumption := range assumptions {
+				growth += assumption.weight * (assumption.mean + assumption.volatility*rng.NormFloat64())
+			}
+			value *= 1 + growth
+			if math.IsInf(value, 1) || math.IsNaN(value) {
+				// Not reachable within the validated bounds, but decimal.NewFromFloat panics on either.
+				value = math.MaxFloat64
+			}
+			withdrawing := query.CurrentAge+y >= query.WithdrawalAge
+			if withdrawing {
+				value -= withdrawal
+			} else {
+				value += contribution
+			}
+			if value <= 0 {
+				value = 0
+				ranOut = ranOut || (withdrawing && withdrawal > 0)
+			}
+			if ranOut {
+				depleted[y]++
+			}
+			outcomes[y][p] = value
+		}
+	}
+
+	result := SimulationResult{
+		Seed:                 seed,
+		Paths:                paths,
+		StartValue:           decimal.NewFromFloat(total).Truncate(2),
+		Allocation:           allocation,
+		Years:                make([]SimulationYear, years),
+		DepletionProbability: decimal.NewFromInt(0),
+	}
+	for y := 0; y < years; y++ {
+		sort.Float64s(outcomes[y])
+		year := SimulationYear{
+			Age:                 query.CurrentAge + y + 1,
+			Percentiles:         map[string]decimal.Decimal{},
+			DepletedProbability: decimal.NewFromFloat(float64(depleted[y]) / float64(paths) * 100).Truncate(2),
+		}
+		for _, p := range percentiles {
+			year.Percentiles[fmt.Sprintf("p%d", p)] = decimal.NewFromFloat(percentile(outcomes[y], p)).Truncate(2)
+		}
+		result.Years[y] = year
+	}
+	if years > 0 {
+		result.DepletionProbability = result.Years[years-1].DepletedProbability
+	}
+	return result
+}
@@ -0,0 +1,109 @@
+package simulation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/shopspring/decimal"
+)
+
+func testSnapshots() []models.StockSnapshot {
+	date := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	stock := func(id uint, class string) models.Stock {
+		return models.Stock{ID: id, ClassCompositionMap: map[string]decimal.Decimal{class: decimal.NewFromInt(100)}}
+	}
+	return []models.StockSnapshot{
+		{AccountID: 1, StockID: 1, Stock: stock(1, "Equity"), Date: date, Value: decimal.NewFromInt(60000)},
+		{AccountID: 1, StockID: 2, Stock: stock(2, "Bond"), Date: date, Value: decimal.NewFromInt(40000)},
+	}
+}
+
+func testQuery() models.SimulationRequest {
+	return models.SimulationRequest{
+		CurrentAge:          60,
+		EndAge:              70,
+		WithdrawalAge:       65,
+		MonthlyContribution: decimal.NewFromInt(500),
+		AnnualWithdrawal:    decimal.NewFromInt(20000),
+		Assumptions: map[string]models.ReturnAssumption{
+			"Equity": {Return: 7, Volatility: 15},
+			"Bond":   {Return: 3, Volatility: 5},
+		},
+		Paths: 500,
+		Seed:  42,
+	}
+}
+
+func TestSimulateIsDeterministic(t *testing.T) {
+	first := Simulate(testQuery(), testSnapshots())
+	second := Simulate(testQuery(), testSnapshots())
+	for y := range first.Years {
+		for p, value := range first.Years[y].Percentiles {
+			if !value.Equal(second.Years[y].Percentiles[p]) {
+				t.Fatalf("age %d %s: %s != %s", first.Years[y].Age, p, value, second.Years[y].Percentiles[p])
+			}
+		}
+	}
+
+	if !first.StartValue.Equal(decimal.NewFromInt(100000)) {
+		t.Errorf("start value: got %s", first.StartValue)
+	}
+	if !first.Allocation["Equity"].Equal(decimal.NewFromInt(60)) || !first.Allocation["Bond"].Equal(decimal.NewFromInt(40)) {
+		t.Errorf("allocation: got %v", first.Allocation)
+	}
+	if len(first.Years) != 10 || first.Years[9].Age != 70 {
+		t.Fatalf("years: got %d", len(first.Years))
+	}
+	expected := map[string]string{
+		"p10": "43903.41",
+		"p25": "66364.87",
+		"p50": "97651.72",
+		"p75": "131616.27",
+		"p90": "164181.35",
+	}
+	for p, value := range expected {
+		if got := first.Years[9].Percentiles[p]; !got.Equal(decimal.RequireFromString(value)) {
+			t.Errorf("final %s: got %s, expected %s", p, got, value)
+		}
+	}
+	if got := first.Years[4].Percentiles["p50"]; !got.Equal(decimal.RequireFromString("160925.89")) {
+		t.Errorf("p50 at withdrawal age: got %s", got)
+	}
+	if !first.DepletionProbability.Equal(decimal.RequireFromString("0.6")) {
+		t.Errorf("depletion probability: got %s", first.DepletionProbability)
+	}
+}
+
+func TestSimulateDepletion(t *testing.T) {
+	query := testQuery()
+	query.AnnualWithdrawal = decimal.NewFromInt(100000)
+	result := Simulate(query, testSnapshots())
+	if !result.Years[4].DepletedProbability.IsZero() {
+		t.Errorf("depleted before withdrawals: got %s", result.Years[4].DepletedProbability)
+	}
+	if !result.DepletionProbability.Equal(decimal.NewFromInt(100)) {
+		t.Errorf("depletion probability: got %s", result.DepletionProbability)
+	}
+	for _, year := range result.Years[5:] {
+		if year.DepletedProbability.LessThan(result.Years[5].DepletedProbability) {
+			t.Errorf("age %d: depletion decreased to %s", year.Age, year.DepletedProbability)
+		}
+	}
+}
+
+func TestIsSimulationValidBounds(t *testing.T) {
+	if !IsSimulationValid(testQuery()) {
+		t.Fatal("valid query rejected")
+	}
+	query := testQuery()
+	query.DefaultAssumption = models.ReturnAssumption{Return: 1e6, Volatility: 1e6}
+	if IsSimulationValid(query) {
+		t.Error("unbounded default assumption accepted")
+	}
+	query = testQuery()
+	query.MonthlyContribution = decimal.New(1, 20)
+	if IsSimulationValid(query) {
+		t.Error("unbounded contribution accepted")
+	}
+}
@@ -0,0 +1,20 @@
+package simulation
+
+import "github.com/shopspring/decimal"
+
+type SimulationYear struct {
+	Age         int                        `json:"age"`
+	Percentiles map[string]decimal.Decimal `json:"percentiles"` // p10, p25, p50, p75, p90
+	// Proportion of paths which have run out of money by the end of this year, as a percentage.
+	DepletedProbability decimal.Decimal `json:"depleted_probability"`
+}
+
+type SimulationResult struct {
+	Seed       int64                      `json:"seed"`
+	Paths      int                        `json:"paths"`
+	StartValue decimal.Decimal            `json:"start_value"`
+	Allocation map[string]decimal.Decimal `json:"allocation"` // class -> percentage
+	Years      []SimulationYear           `json:"years"`
+	// Proportion of paths which run out of money before the end age, as a percentage.
+	DepletionProbability decimal.Decimal `json:"depletion_probability"`
+}
@@ -76,3 +76,26 @@ func CalculateSplit(grouped splitMap) map[string]decimal.Decimal {
 	}
 	return split
 }
+
+// The latest snapshot of each holding, excluding those which are no longer present
+// in their account's most recent snapshot.
+func CurrentHoldings(snapshots []models.StockSnapshot) []models.StockSnapshot {
+	accountLatest := map[uint]time.Time{}
+	latest := map[string]models.StockSnapshot{}
+	for _, snapshot := range snapshots {
+		if date, ok := accountLatest[snapshot.AccountID]; !ok || snapshot.Date.After(date) {
+			accountLatest[snapshot.AccountID] = snapshot.Date
+		}
+		key := snapshot.Key()
+		if existing, ok := latest[key]; !ok || snapshot.Date.After(existing.Date) {
+			latest[key] = snapshot
+		}
+	}
+	out := []models.StockSnapshot{}
+	for _, snapshot := range latest {
+		if !snapshot.Date.Before(accountLatest[snapshot.AccountID]) && !snapshot.Value.IsZero() {
+			out = append(out, snapshot)
+		}
+	}
+	return out
+}
@@ -14,7 +14,7 @@ func ProcessSnapshots(snapshots []models.StockSnapshot, info PerformanceQueryInf
 	timeCategories := util.NewOrderedSet[string]()
 	for _, snapshot := range snapshots {
 		targetValues := extraction.GetKeysFromSnapshot(snapshot, info.TargetKey)
-		timeCategory := extraction.ExtractTimeFromSnapshot(times.PerformanceTimeExtractionSet(), info.TimeKey, snapshot)
+		timeCategory := extraction.ExtractTimeFromSnapshot(times.PerformanceTimeExtractionSet(info.FiscalYear), info.TimeKey, snapshot)
 		timeCategories.Add(timeCategory)
 		againstValues := extraction.GetKeysFromSnapshot(snapshot, info.AgainstKey)
 
@@ -32,7 +32,7 @@ func ProcessSnapshots(snapshots []models.StockSnapshot, info PerformanceQueryInf
 		delete(groups, constants.TRENDS_SUMMARY)
 	}
 	timePeriods := extraction.ExtractTimeList(info.TimeKey, timeCategories.Items())
-	focusTime := util.Map(timePeriods, extraction.GetTimeFocusFunction(info.TimeKey))
+	focusTime := util.Map(timePeriods, extraction.GetTimeFocusFunction(info.TimeKey, info.FiscalYear))
 	return groups, append(timePeriods, info.Meta.SummaryLabel), focusTime
 }
 
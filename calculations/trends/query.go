@@ -10,10 +10,11 @@ import (
 
 func IsPerformanceQueryValid(p PerformanceQueryInfo) bool {
 	// TODO: split keys (eg. class) can be valid for some metrics eg. holdings.
-	return slices.Contains(extraction.SingleTargets(), p.TargetKey) &&
-		slices.Contains(extraction.SingleTargets(), p.AgainstKey) &&
+	targets := append(extraction.SingleTargets(), extraction.GroupingTargets()...)
+	return slices.Contains(targets, p.TargetKey) &&
+		slices.Contains(targets, p.AgainstKey) &&
 		slices.Contains(metrics.GetMetricNames(), p.MetricKey) &&
-		slices.Contains(extraction.TimeKeys(times.PerformanceTimeExtractionSet()), p.TimeKey)
+		slices.Contains(extraction.TimeKeys(times.PerformanceTimeExtractionSet(p.FiscalYear)), p.TimeKey)
 }
 
 func SetQueryMeta(p *PerformanceQueryInfo) {
@@ -2,6 +2,7 @@ package trends
 
 import (
 	"github.com/goldsproutapp/goldsprout-backend/calculations/trends/metrics"
+	"github.com/goldsproutapp/goldsprout-backend/lib/extraction/times"
 	"github.com/goldsproutapp/goldsprout-backend/models"
 	"github.com/shopspring/decimal"
 )
@@ -14,6 +15,7 @@ type PerformanceQueryInfo struct {
 	Meta           metrics.PerformanceMetricMeta
 	MetricFunction metrics.PerformanceMetricFunction
 	LatestOnly     bool
+	FiscalYear     times.YearStart
 }
 
 func (i *PerformanceQueryInfo) GenerateSummary() bool {
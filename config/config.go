@@ -1,6 +1,9 @@
 package config
 
-import "os"
+import (
+	"os"
+	"time"
+)
 
 func EnvOrDefault(key string, def string) string {
 	value, set := os.LookupEnv(key)
@@ -10,6 +13,15 @@ func EnvOrDefault(key string, def string) string {
 	return value
 }
 
+// A duration such as "720h". An unset or invalid value gives the default.
+func EnvDurationOrDefault(key string, def time.Duration) time.Duration {
+	value, err := time.ParseDuration(EnvOrDefault(key, ""))
+	if err != nil {
+		return def
+	}
+	return value
+}
+
 func RequiredEnv(key string) string {
 	value, set := os.LookupEnv(key)
 	if !set {
@@ -21,3 +33,29 @@ func RequiredEnv(key string) string {
 func DemoModeEnabled() bool {
 	return EnvOrDefault(ENVKEY_DEMO_MODE_ENABLED, "false") == "true"
 }
+
+func StatementArchiveEnabled() bool {
+	return EnvOrDefault(ENVKEY_STATEMENT_ARCHIVE_ENABLED, "false") == "true"
+}
+
+// A timeout of zero disables it.
+func SessionIdleTimeout() time.Duration {
+	return EnvDurationOrDefault(ENVKEY_SESSION_IDLE_TIMEOUT, 30*24*time.Hour)
+}
+
+func SessionAbsoluteTimeout() time.Duration {
+	return EnvDurationOrDefault(ENVKEY_SESSION_ABSOLUTE_TIMEOUT, 90*24*time.Hour)
+}
+
+func LocalLoginEnabled() bool {
+	return EnvOrDefault(ENVKEY_LOCAL_LOGIN_ENABLED, "true") == "true"
+}
+
+func OIDCEnabled() bool {
+	return EnvOrDefault(ENVKEY_OIDC_ISSUER, "") != ""
+}
+
+// Whether invited users who have not yet accepted their invitation are activated on their first OIDC login.
+func OIDCJITProvisioningEnabled() bool {
+	return EnvOrDefault(ENVKEY_OIDC_JIT_PROVISIONING, "false") == "true"
+}
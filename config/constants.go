@@ -21,9 +21,32 @@ const (
 	LISTEN_PORT       = "LISTEN_PORT"
 )
 
+const (
+	ENVKEY_FISCAL_YEAR_START = "FISCAL_YEAR_START" // MM-DD; defaults to 04-06
+)
+
 const (
 	ENVKEY_DEMO_MODE_ENABLED    = "ENABLE_DEMO_MODE"
 	ENVKEY_DEMO_USER_EMAIL      = "DEMO_USER_EMAIL"
 	ENVKEY_DEMO_USER_FIRST_NAME = "DEMO_USER_FIRST_NAME"
 	ENVKEY_DEMO_USER_LAST_NAME  = "DEMO_USER_LAST_NAME"
 )
+
+const (
+	ENVKEY_STATEMENT_ARCHIVE_ENABLED = "ENABLE_STATEMENT_ARCHIVE"
+)
+
+const (
+	ENVKEY_SESSION_IDLE_TIMEOUT     = "SESSION_IDLE_TIMEOUT"     // eg. 720h
+	ENVKEY_SESSION_ABSOLUTE_TIMEOUT = "SESSION_ABSOLUTE_TIMEOUT" // eg. 2160h
+)
+
+const (
+	ENVKEY_LOCAL_LOGIN_ENABLED   = "ENABLE_LOCAL_LOGIN"
+	ENVKEY_OIDC_ISSUER           = "OIDC_ISSUER" // OIDC login is enabled when this is set.
+	ENVKEY_OIDC_CLIENT_ID        = "OIDC_CLIENT_ID"
+	ENVKEY_OIDC_CLIENT_SECRET    = "OIDC_CLIENT_SECRET" // may be empty for public clients
+	ENVKEY_OIDC_REDIRECT_URL     = "OIDC_REDIRECT_URL"  // defaults to <FRONTEND_BASE_URL>/login/oidc
+	ENVKEY_OIDC_SCOPES           = "OIDC_SCOPES"        // space-separated; defaults to "openid email profile"
+	ENVKEY_OIDC_JIT_PROVISIONING = "OIDC_JIT_PROVISIONING"
+)
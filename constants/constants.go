@@ -1,7 +1,10 @@
 package constants
 
+import "time"
+
 const (
 	LABEL_UNCATEGORISED = "Uncategorised"
+	LABEL_UNTAGGED      = "Untagged"
 )
 
 const (
@@ -11,6 +14,29 @@ const (
 	DEFAULT_CLASS_NAME             = LABEL_UNCATEGORISED
 	TOKEN_LENGTH                   = 64
 	PERFORMANCE_DECIMAL_DIGITS     = 2
+	DEFAULT_DRIFT_TOLERANCE        = 5
+	MAX_TAG_LENGTH                 = 64
+	DEFAULT_SIMULATION_PATHS       = 1000
+	MAX_SIMULATION_PATHS           = 10000
+	MAX_SIMULATION_RATE            = 100           // percent, for both return and volatility
+	MAX_SIMULATION_AMOUNT          = 1_000_000_000 // for both contributions and withdrawals
+)
+
+const (
+	GOAL_LINK_ACCOUNT = "account"
+	GOAL_LINK_HOLDING = "holding"
+	GOAL_LINK_TAG     = "tag"
+)
+
+const (
+	GOAL_STATUS_ACHIEVED  = "achieved"
+	GOAL_STATUS_ON_TRACK  = "on_track"
+	GOAL_STATUS_OFF_TRACK = "off_track"
+)
+
+const (
+	COMPOSITION_REGION = "region"
+	COMPOSITION_SECTOR = "sector"
 )
 
 const (
@@ -21,6 +47,11 @@ const (
 
 const ISO8601 = "2006-01-02"
 
+const (
+	TAX_YEAR_START_MONTH = time.April
+	TAX_YEAR_START_DAY   = 6
+)
+
 var MONTHS = []string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
 
 var DEMO_USER_AUTH_TOKEN = "Demo-User"
@@ -29,3 +60,75 @@ const TRENDS_SUMMARY = "Summary"
 
 const TransAttrBuySell = 0
 const TransAttrIncomeFee = 1
+
+const (
+	ACCOUNT_TYPE_GENERAL = "GENERAL"
+	ACCOUNT_TYPE_ISA     = "ISA"
+	ACCOUNT_TYPE_PENSION = "PENSION"
+)
+
+var ACCOUNT_TYPES = []string{ACCOUNT_TYPE_GENERAL, ACCOUNT_TYPE_ISA, ACCOUNT_TYPE_PENSION}
+var TAX_SHELTERED_ACCOUNT_TYPES = []string{ACCOUNT_TYPE_ISA, ACCOUNT_TYPE_PENSION}
+
+const (
+	ASSET_TYPE_CASH     = "CASH"
+	ASSET_TYPE_PROPERTY = "PROPERTY"
+	ASSET_TYPE_VEHICLE  = "VEHICLE"
+	ASSET_TYPE_OTHER    = "OTHER"
+	ASSET_TYPE_MORTGAGE = "MORTGAGE"
+	ASSET_TYPE_LOAN     = "LOAN"
+)
+
+var ASSET_TYPES = []string{ASSET_TYPE_CASH, ASSET_TYPE_PROPERTY, ASSET_TYPE_VEHICLE, ASSET_TYPE_OTHER, ASSET_TYPE_MORTGAGE, ASSET_TYPE_LOAN}
+var LIABILITY_ASSET_TYPES = []string{ASSET_TYPE_MORTGAGE, ASSET_TYPE_LOAN}
+
+const (
+	STATEMENT_PERIOD_MONTHLY   = "monthly"
+	STATEMENT_PERIOD_QUARTERLY = "quarterly"
+	STATEMENT_PERIOD_ANNUAL    = "annual"
+)
+
+var STATEMENT_PERIODS = []string{STATEMENT_PERIOD_MONTHLY, STATEMENT_PERIOD_QUARTERLY, STATEMENT_PERIOD_ANNUAL}
+
+const EXPORT_BATCH_SIZE = 500
+
+const (
+	JOURNAL_FORMAT_BEANCOUNT = "beancount"
+	JOURNAL_FORMAT_HLEDGER   = "hledger"
+)
+
+var JOURNAL_FORMATS = []string{JOURNAL_FORMAT_BEANCOUNT, JOURNAL_FORMAT_HLEDGER}
+
+const DEFAULT_JOURNAL_CURRENCY = "GBP"
+
+const (
+	TOTP_ISSUER              = "Goldsprout"
+	TOTP_DIGITS              = 6
+	TOTP_PERIOD              = 30 // seconds
+	TOTP_SKEW                = 1  // steps either side of the current one which are accepted
+	RECOVERY_CODE_COUNT      = 10
+	RECOVERY_CODE_LENGTH     = 10
+	LOGIN_CHALLENGE_DURATION = 5 * time.Minute
+	LOGIN_CHALLENGE_ATTEMPTS = 5
+	// Failed codes allowed per user, across all their challenges, before new challenges are refused.
+	TWO_FACTOR_MAX_FAILURES = 10
+	TWO_FACTOR_LOCKOUT      = 15 * time.Minute
+)
+
+const SESSION_TOUCH_INTERVAL = time.Minute // how often last_used_at is updated
+
+const PASSWORD_RESET_DURATION = time.Hour
+
+const OIDC_STATE_DURATION = 10 * time.Minute
+
+const (
+	API_TOKEN_PREFIX = "gsp_"
+
+	API_SCOPE_READ           = "read"
+	API_SCOPE_SNAPSHOT_WRITE = "snapshot_write"
+	API_SCOPE_ADMIN          = "admin" // unrestricted
+
+	API_TOKEN_USE_LOG_LIMIT = 100 // number of uses returned
+)
+
+var API_SCOPES = []string{API_SCOPE_READ, API_SCOPE_SNAPSHOT_WRITE, API_SCOPE_ADMIN}
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+func GetAssets(db *gorm.DB, uids []uint) ([]models.Asset, error) {
+	var assets []models.Asset
+	res := db.Model(&models.Asset{}).Where("user_id IN ?", uids).
+		Preload("Valuations", func(db *gorm.DB) *gorm.DB {
+			return db.Order("date")
+		}).Find(&assets)
+	return assets, res.Error
+}
+
+func GetAsset(db *gorm.DB, id uint) (models.Asset, error) {
+	var asset models.Asset
+	res := db.Model(&models.Asset{}).Where("id = ?", id).
+		Preload("Valuations", func(db *gorm.DB) *gorm.DB {
+			return db.Order("date")
+		}).First(&asset)
+	return asset, res.Error
+}
+
+func GetValuation(db *gorm.DB, assetID uint, id uint) (models.AssetValuation, error) {
+	var valuation models.AssetValuation
+	res := db.Model(&models.AssetValuation{}).Where("asset_id = ? AND id = ?", assetID, id).First(&valuation)
+	return valuation, res.Error
+}
+
+func DeleteAsset(db *gorm.DB, asset models.Asset) {
+	db.Where("asset_id = ?", asset.ID).Delete(&models.AssetValuation{})
+	db.Delete(&asset)
+}
+
+// The latest balances of the user's assets and liabilities.
+func GetAssetTotals(db *gorm.DB, uid uint) (decimal.Decimal, decimal.Decimal) {
+	assets := decimal.NewFromInt(0)
+	liabilities := decimal.NewFromInt(0)
+	objs, _ := GetAssets(db, []uint{uid})
+	for _, asset := range objs {
+		if len(asset.Valuations) == 0 {
+			continue
+		}
+		value := asset.Valuations[len(asset.Valuations)-1].Value
+		if asset.IsLiability() {
+			liabilities = liabilities.Add(value)
+		} else {
+			assets = assets.Add(value)
+		}
+	}
+	return assets, liabilities
+}
@@ -3,6 +3,7 @@ package database
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/goldsproutapp/goldsprout-backend/auth"
 	"github.com/goldsproutapp/goldsprout-backend/config"
@@ -71,15 +72,37 @@ func InitDB() *gorm.DB {
 	db.AutoMigrate(
 		&models.User{},
 		&models.Session{},
+		&models.RecoveryCode{},
+		&models.LoginChallenge{},
+		&models.PasswordResetToken{},
+		&models.APIToken{},
+		&models.APITokenAccount{},
+		&models.APITokenUse{},
+		&models.OIDCLoginState{},
+		&models.InstanceSettings{},
 		&models.Provider{},
 		&models.Account{},
 		&models.Stock{},
 		&models.UserStock{},
+		&models.UserStockTag{},
 		&models.StockSnapshot{},
 		&models.RegularTransaction{},
 		&models.SingleTransaction{},
 		&models.AccessPermission{},
 		&models.ClassCompositionEntry{},
+		&models.CompositionEntry{},
+		&models.AllocationTarget{},
+		&models.AllocationTargetEntry{},
+		&models.DriftAlert{},
+		&models.Asset{},
+		&models.AssetValuation{},
+		&models.Scenario{},
+		&models.ScenarioShock{},
+		&models.Goal{},
+		&models.GoalLink{},
+		&models.Statement{},
 	)
+	// Sessions from before last use was recorded start their idle timeout from now.
+	db.Model(&models.Session{}).Where("last_used_at IS NULL").UpdateColumn("last_used_at", time.Now())
 	return db
 }
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/goldsproutapp/goldsprout-backend/auth"
+	"github.com/goldsproutapp/goldsprout-backend/constants"
 	"github.com/goldsproutapp/goldsprout-backend/models"
 	"github.com/goldsproutapp/goldsprout-backend/util"
 	"github.com/shopspring/decimal"
@@ -22,13 +23,26 @@ func GetProviders(db *gorm.DB) []models.Provider {
 func GetRegions(db *gorm.DB) []string {
 	var regions []string
 	db.Model(&models.Stock{}).Select("region").Distinct("region").Find(&regions)
-	return regions
+	return withCompositionLabels(db, constants.COMPOSITION_REGION, regions)
 }
 
 func GetSectors(db *gorm.DB) []string {
 	var sectors []string
 	db.Model(&models.Stock{}).Select("sector").Distinct("sector").Find(&sectors)
-	return sectors
+	return withCompositionLabels(db, constants.COMPOSITION_SECTOR, sectors)
+}
+
+func withCompositionLabels(db *gorm.DB, dimension string, labels []string) []string {
+	var compositionLabels []string
+	db.Model(&models.CompositionEntry{}).Where("dimension = ?", dimension).Distinct("label").Find(&compositionLabels)
+	set := util.HashSetFrom(labels)
+	for _, label := range compositionLabels {
+		if !set.Has(label) {
+			labels = append(labels, label)
+			set.Add(label)
+		}
+	}
+	return labels
 }
 
 func GetClasses(db *gorm.DB) []string {
@@ -37,18 +51,25 @@ func GetClasses(db *gorm.DB) []string {
 	return classes
 }
 
-func GetOverview(db *gorm.DB, user models.User) models.OverviewResponse {
-	uids := auth.GetAllowedUsers(user, true, false, false)
+// The users whose data the user can read. Admins can read everyone's.
+func GetVisibleUserIDs(db *gorm.DB, user models.User) []uint {
 	if user.IsAdmin {
-		uids = util.UserIDs(GetAllUsers(db))
+		return util.UserIDs(GetAllUsers(db))
 	}
+	return auth.GetAllowedUsers(user, true, false, false)
+}
+
+func GetOverview(db *gorm.DB, user models.User) models.OverviewResponse {
+	uids := GetVisibleUserIDs(db, user)
 	overviews := map[string]models.OverviewResponseUserEntry{}
 	userOverview := GetOverviewForUser(db, user.ID)
 	aum := userOverview.TotalValue
+	netWorth := userOverview.NetWorth
 	for _, uid := range uids {
 		if uid != user.ID {
 			overview := GetOverviewForUser(db, uid)
 			aum = aum.Add(overview.TotalValue)
+			netWorth = netWorth.Add(overview.NetWorth)
 			overviews[strconv.FormatInt(int64(uid), 10)] = overview
 		}
 	}
@@ -56,6 +77,7 @@ func GetOverview(db *gorm.DB, user models.User) models.OverviewResponse {
 		OverviewResponseUserEntry: userOverview,
 		Users:                     overviews,
 		AUM:                       aum,
+		NetWorth:                  netWorth,
 	}
 }
 
@@ -80,11 +102,15 @@ func GetOverviewForUser(db *gorm.DB, uid uint) models.OverviewResponseUserEntry
 			lastSnapshot = snapshot.Date
 		}
 	}
+	assets, liabilities := GetAssetTotals(db, uid)
 	return models.OverviewResponseUserEntry{
 		TotalValue:    totalValue,
 		AllTimeChange: allTimeChange,
 		NumStocks:     numStocks,
 		NumProviders:  providers.Size(),
 		LastSnapshot:  lastSnapshot,
+		Assets:        assets,
+		Liabilities:   liabilities,
+		NetWorth:      totalValue.Add(assets).Add(liabilities),
 	}
 }
@@ -0,0 +1,36 @@
+package database
+
+import (
+	"github.com/goldsproutapp/goldsprout-backend/auth"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"gorm.io/gorm"
+)
+
+func GetVisibleGoals(db *gorm.DB, user models.User) ([]models.Goal, error) {
+	var goals []models.Goal
+	qry := db.Model(&models.Goal{}).Order("target_date")
+	if !user.IsAdmin {
+		qry = qry.Where("user_id IN ?", auth.GetAllowedUsers(user, true, false, false))
+	}
+	res := qry.Find(&goals)
+	return goals, res.Error
+}
+
+func GetGoal(db *gorm.DB, id uint) (models.Goal, error) {
+	var goal models.Goal
+	res := db.Model(&models.Goal{}).Where("id = ?", id).First(&goal)
+	return goal, res.Error
+}
+
+// The users whose holdings count towards the goal.
+func GetGoalUsers(db *gorm.DB, goal models.Goal) []uint {
+	if !goal.Household {
+		return []uint{goal.UserID}
+	}
+	return GetHouseholdUsers(db, goal.UserID)
+}
+
+func DeleteGoal(db *gorm.DB, goal models.Goal) {
+	db.Where("goal_id = ?", goal.ID).Delete(&models.GoalLink{})
+	db.Delete(&goal)
+}
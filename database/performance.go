@@ -9,7 +9,7 @@ import (
 	"gorm.io/gorm"
 )
 
-func GetFilteredSnapshots(db *gorm.DB, user models.User, filter StockFilter, permitLimited bool) []models.StockSnapshot {
+func filteredSnapshotQuery(db *gorm.DB, user models.User, filter StockFilter, permitLimited bool) *gorm.DB {
 	uids := auth.GetAllowedUsers(user, true, false, permitLimited)
 	if user.IsAdmin {
 		uids = util.UserIDs(GetAllUsers(db))
@@ -24,7 +24,6 @@ func GetFilteredSnapshots(db *gorm.DB, user models.User, filter StockFilter, per
 		uids = intersection
 	}
 	qry := db.Model(&models.StockSnapshot{}).
-		Order("date").
 		Joins("User").
 		Joins("Stock").
 		Joins("Account").
@@ -39,14 +38,70 @@ func GetFilteredSnapshots(db *gorm.DB, user models.User, filter StockFilter, per
 	if len(filter.Accounts) > 0 {
 		qry = qry.Where("Account.name IN ?", filter.Accounts)
 	}
+	if len(filter.Tags) > 0 {
+		qry = qry.Where("EXISTS (?)", db.Model(&models.UserStockTag{}).
+			Select("1").
+			Joins("INNER JOIN user_stocks ON user_stocks.id = user_stock_tags.user_stock_id").
+			Where("user_stocks.account_id = stock_snapshots.account_id AND user_stocks.stock_id = stock_snapshots.stock_id").
+			Where("user_stock_tags.tag IN ?", filter.Tags))
+	}
 	if filter.LowerDate.Unix() != 0 {
 		qry = qry.Where("date > ?", filter.LowerDate)
 	}
 	if filter.UpperDate.Unix() != 0 {
 		qry = qry.Where("date < ?", filter.UpperDate)
 	}
+	return qry
+}
 
+func GetFilteredSnapshots(db *gorm.DB, user models.User, filter StockFilter, permitLimited bool) []models.StockSnapshot {
 	var snapshots []models.StockSnapshot
-	qry.Find(&snapshots)
+	filteredSnapshotQuery(db, user, filter, permitLimited).Order("date").Find(&snapshots)
+	AttachTags(db, snapshots)
+	AttachSnapshotCompositions(db, snapshots)
 	return snapshots
 }
+
+// Pass the filtered snapshots to fn in date order, batchSize at a time, stopping at the first error.
+// If latestOnly is set, only the most recent snapshot batch of each account (within the filter's
+// date range) is included, excluding holdings which have been sold.
+func StreamFilteredSnapshots(db *gorm.DB, user models.User, filter StockFilter, permitLimited bool, latestOnly bool,
+	batchSize int, fn func([]models.StockSnapshot) error) error {
+	var last *models.StockSnapshot
+	for {
+		qry := filteredSnapshotQuery(db, user, filter, permitLimited)
+		if latestOnly {
+			latest := db.Table("stock_snapshots AS latest").
+				Select("MAX(latest.date)").
+				Where("latest.account_id = stock_snapshots.account_id")
+			if filter.LowerDate.Unix() != 0 {
+				latest = latest.Where("latest.date > ?", filter.LowerDate)
+			}
+			if filter.UpperDate.Unix() != 0 {
+				latest = latest.Where("latest.date < ?", filter.UpperDate)
+			}
+			qry = qry.Where("stock_snapshots.value <> 0 AND stock_snapshots.date = (?)", latest)
+		}
+		if last != nil {
+			qry = qry.Where("(stock_snapshots.date > ? OR (stock_snapshots.date = ? AND stock_snapshots.id > ?))",
+				last.Date, last.Date, last.ID)
+		}
+		var batch []models.StockSnapshot
+		res := qry.Order("stock_snapshots.date").Order("stock_snapshots.id").Limit(batchSize).Find(&batch)
+		if res.Error != nil {
+			return res.Error
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+		AttachTags(db, batch)
+		AttachSnapshotCompositions(db, batch)
+		if err := fn(batch); err != nil {
+			return err
+		}
+		if len(batch) < batchSize {
+			return nil
+		}
+		last = &batch[len(batch)-1]
+	}
+}
@@ -7,6 +7,7 @@ type StockFilter struct {
 	Providers []uint
 	Users     []uint
 	Accounts  []string
+	Tags      []string
 	LowerDate time.Time
 	UpperDate time.Time
 }
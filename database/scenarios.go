@@ -0,0 +1,41 @@
+package database
+
+import (
+	"github.com/goldsproutapp/goldsprout-backend/auth"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"gorm.io/gorm"
+)
+
+func GetVisibleScenarios(db *gorm.DB, user models.User) ([]models.Scenario, error) {
+	var scenarios []models.Scenario
+	qry := db.Model(&models.Scenario{}).Preload("Shocks")
+	if !user.IsAdmin {
+		qry = qry.Where("user_id IN ?", auth.GetAllowedUsers(user, true, false, false))
+	}
+	res := qry.Find(&scenarios)
+	return scenarios, res.Error
+}
+
+func GetScenario(db *gorm.DB, id uint) (models.Scenario, error) {
+	var scenario models.Scenario
+	res := db.Model(&models.Scenario{}).Where("id = ?", id).Preload("Shocks").First(&scenario)
+	return scenario, res.Error
+}
+
+func ReplaceScenarioShocks(db *gorm.DB, scenario *models.Scenario, shocks []models.ScenarioShock) {
+	db.Where("scenario_id = ?", scenario.ID).Delete(&models.ScenarioShock{})
+	scenario.Shocks = make([]models.ScenarioShock, len(shocks))
+	for i, shock := range shocks {
+		shock.ID = 0
+		shock.ScenarioID = scenario.ID
+		scenario.Shocks[i] = shock
+	}
+	if len(scenario.Shocks) > 0 {
+		db.Create(&scenario.Shocks)
+	}
+}
+
+func DeleteScenario(db *gorm.DB, scenario models.Scenario) {
+	db.Where("scenario_id = ?", scenario.ID).Delete(&models.ScenarioShock{})
+	db.Delete(&scenario)
+}
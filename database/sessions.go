@@ -0,0 +1,24 @@
+package database
+
+import (
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"gorm.io/gorm"
+)
+
+func GetUserSessions(db *gorm.DB, userID uint) ([]models.Session, error) {
+	var sessions []models.Session
+	res := db.Model(&models.Session{}).Where("user_id = ?", userID).Order("last_used_at DESC").Find(&sessions)
+	return sessions, res.Error
+}
+
+func GetUserSession(db *gorm.DB, userID uint, id uint) (models.Session, error) {
+	var session models.Session
+	res := db.Model(&models.Session{}).Where("id = ? AND user_id = ?", id, userID).First(&session)
+	return session, res.Error
+}
+
+// Revoke every session for the user except the given one, along with any pending login challenges.
+func RevokeOtherSessions(db *gorm.DB, userID uint, except uint) {
+	db.Where("user_id = ? AND id <> ?", userID, except).Delete(&models.Session{})
+	db.Where("user_id = ?", userID).Delete(&models.LoginChallenge{})
+}
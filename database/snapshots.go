@@ -75,3 +75,16 @@ func GetSnapshots(users []uint, stocks []uint, db *gorm.DB, preload ...string) [
 	return snapshots
 }
 
+// Prices of the given stocks from all snapshots, regardless of who holds them.
+func GetPriceHistory(db *gorm.DB, stockIDs []uint) []models.StockSnapshot {
+	var prices []models.StockSnapshot
+	if len(stockIDs) == 0 {
+		return prices
+	}
+	db.Model(&models.StockSnapshot{}).
+		Select("stock_id", "date", "price").
+		Where("stock_id IN ?", stockIDs).
+		Order("date").
+		Find(&prices)
+	return prices
+}
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"time"
+
+	"github.com/goldsproutapp/goldsprout-backend/auth"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"gorm.io/gorm"
+)
+
+// Archived statements, without their content.
+func GetVisibleStatements(db *gorm.DB, user models.User) ([]models.Statement, error) {
+	var statements []models.Statement
+	qry := db.Model(&models.Statement{}).Omit("content").Order("start DESC")
+	if !user.IsAdmin {
+		qry = qry.Where("user_id IN ?", auth.GetAllowedUsers(user, true, false, false))
+	}
+	res := qry.Find(&statements)
+	if res.Error != nil {
+		return statements, res.Error
+	}
+	households := map[uint]bool{} // owner -> whether user can view their household statements
+	visible := []models.Statement{}
+	for _, statement := range statements {
+		if statement.Household {
+			if _, ok := households[statement.UserID]; !ok {
+				households[statement.UserID] = CanViewStatement(db, user, statement)
+			}
+			if !households[statement.UserID] {
+				continue
+			}
+		}
+		visible = append(visible, statement)
+	}
+	return visible, nil
+}
+
+// Archived household statements are rendered with the owner as the viewer, so they can only
+// be viewed by users who can read every member of the owner's household.
+func CanViewStatement(db *gorm.DB, user models.User, statement models.Statement) bool {
+	users := []uint{statement.UserID}
+	if statement.Household {
+		users = GetHouseholdUsers(db, statement.UserID)
+	}
+	for _, uid := range users {
+		if !auth.HasAccessPerm(user, uid, true, false, false) {
+			return false
+		}
+	}
+	return true
+}
+
+func GetStatement(db *gorm.DB, id uint) (models.Statement, error) {
+	var statement models.Statement
+	res := db.Model(&models.Statement{}).Where("id = ?", id).First(&statement)
+	return statement, res.Error
+}
+
+func StatementExists(db *gorm.DB, userID uint, household bool, period string, start time.Time) bool {
+	var count int64
+	db.Model(&models.Statement{}).
+		Where("user_id = ? AND household = ? AND period = ? AND start = ?", userID, household, period, start).
+		Count(&count)
+	return count > 0
+}
+
+// The end of the most recent archived statement, if there is one.
+func LastStatementEnd(db *gorm.DB, userID uint, household bool, period string) (time.Time, bool) {
+	var statement models.Statement
+	res := db.Model(&models.Statement{}).Select("end").
+		Where("user_id = ? AND household = ? AND period = ?", userID, household, period).
+		Order("end DESC").First(&statement)
+	return statement.End, Exists(res)
+}
@@ -3,6 +3,7 @@ package database
 import (
 	"github.com/goldsproutapp/goldsprout-backend/auth"
 	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/goldsproutapp/goldsprout-backend/util"
 	"github.com/goldsproutapp/goldsprout-backend/util/tristate"
 	"gorm.io/gorm"
 )
@@ -20,9 +21,42 @@ func GetUserStocks(authUser models.User, db *gorm.DB, heldBy []uint, currentlyHe
 		query = query.Where("user_id IN ?", auth.GetAllowedUsers(authUser, true, false, permitLimited))
 	}
 	query.Find(&userStocks)
+	stocks := make([]*models.Stock, len(userStocks))
+	for i := range userStocks {
+		stocks[i] = &userStocks[i].Stock
+	}
+	AttachCompositions(db, stocks)
 	return userStocks
 }
 
+// Load the look-through composition of each stock, in a single query.
+func AttachCompositions(db *gorm.DB, stocks []*models.Stock) {
+	if len(stocks) == 0 {
+		return
+	}
+	ids := util.NewHashSet[uint]()
+	for _, stock := range stocks {
+		ids.Add(stock.ID)
+	}
+	var entries []models.CompositionEntry
+	db.Model(&models.CompositionEntry{}).Where("stock_id IN ?", ids.Items()).Find(&entries)
+	byStock := map[uint][]models.CompositionEntry{}
+	for _, entry := range entries {
+		byStock[entry.StockID] = append(byStock[entry.StockID], entry)
+	}
+	for _, stock := range stocks {
+		stock.SetComposition(byStock[stock.ID])
+	}
+}
+
+func AttachSnapshotCompositions(db *gorm.DB, snapshots []models.StockSnapshot) {
+	stocks := make([]*models.Stock, len(snapshots))
+	for i := range snapshots {
+		stocks[i] = &snapshots[i].Stock
+	}
+	AttachCompositions(db, stocks)
+}
+
 func GetVisibleStockList(user models.User, db *gorm.DB, permitLimited bool) []models.UserStock {
 	return GetUserStocks(user, db, []uint{}, tristate.None(), permitLimited)
 }
@@ -82,6 +116,20 @@ func GetUserStock(db *gorm.DB, uid uint, stockID uint, accountID uint) (models.U
 	return obj, result.Error
 }
 
+func GetUserStockByID(db *gorm.DB, id uint) (models.UserStock, error) {
+	var obj models.UserStock
+	result := db.Model(models.UserStock{}).Where("id = ?", id).First(&obj)
+	return obj, result.Error
+}
+
+func GetUserStocksByID(db *gorm.DB, ids []uint) []models.UserStock {
+	userStocks := []models.UserStock{}
+	if len(ids) > 0 {
+		db.Model(models.UserStock{}).Where("id IN ?", ids).Find(&userStocks)
+	}
+	return userStocks
+}
+
 func GetUsersHoldingStock(db *gorm.DB, stockID uint) ([]uint, error) {
 	var userStocks []models.UserStock
 	result := db.Model(models.UserStock{}).Where("stock_id = ?", stockID).Find(&userStocks)
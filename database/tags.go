@@ -0,0 +1,89 @@
+package database
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/goldsproutapp/goldsprout-backend/auth"
+	"github.com/goldsproutapp/goldsprout-backend/constants"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"gorm.io/gorm"
+)
+
+func NormaliseTags(tags []string) []string {
+	out := []string{}
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || len(tag) > constants.MAX_TAG_LENGTH || slices.Contains(out, tag) {
+			continue
+		}
+		out = append(out, tag)
+	}
+	return out
+}
+
+func SetUserStockTags(db *gorm.DB, userStockID uint, tags []string) {
+	db.Where("user_stock_id = ?", userStockID).Delete(&models.UserStockTag{})
+	if len(tags) == 0 {
+		return
+	}
+	objs := make([]models.UserStockTag, len(tags))
+	for i, tag := range tags {
+		objs[i] = models.UserStockTag{UserStockID: userStockID, Tag: tag}
+	}
+	db.Create(&objs)
+}
+
+// Move the tags of one holding onto another, which keeps any tags it already has.
+func MergeUserStockTags(db *gorm.DB, fromID uint, intoID uint) {
+	var tags []string
+	db.Model(&models.UserStockTag{}).Where("user_stock_id IN ?", []uint{intoID, fromID}).Pluck("tag", &tags)
+	SetUserStockTags(db, intoID, NormaliseTags(tags))
+	db.Where("user_stock_id = ?", fromID).Delete(&models.UserStockTag{})
+}
+
+func GetVisibleTags(db *gorm.DB, user models.User) []string {
+	var tags []string
+	qry := db.Model(&models.UserStockTag{}).
+		Joins("INNER JOIN user_stocks ON user_stocks.id = user_stock_tags.user_stock_id")
+	if !user.IsAdmin {
+		qry = qry.Where("user_stocks.user_id IN ?", auth.GetAllowedUsers(user, true, false, true))
+	}
+	qry.Distinct("tag").Order("tag").Pluck("tag", &tags)
+	return tags
+}
+
+// Populate the tags of the holding each snapshot belongs to.
+func AttachTags(db *gorm.DB, snapshots []models.StockSnapshot) {
+	if len(snapshots) == 0 {
+		return
+	}
+	accountIDs := map[uint]bool{}
+	for _, snapshot := range snapshots {
+		accountIDs[snapshot.AccountID] = true
+	}
+	ids := make([]uint, 0, len(accountIDs))
+	for id := range accountIDs {
+		ids = append(ids, id)
+	}
+	var rows []struct {
+		AccountID uint
+		StockID   uint
+		Tag       string
+	}
+	db.Model(&models.UserStockTag{}).
+		Select("user_stocks.account_id, user_stocks.stock_id, user_stock_tags.tag").
+		Joins("INNER JOIN user_stocks ON user_stocks.id = user_stock_tags.user_stock_id").
+		Where("user_stocks.account_id IN ?", ids).
+		Order("user_stock_tags.tag").
+		Scan(&rows)
+	tagMap := map[string][]string{}
+	for _, row := range rows {
+		key := fmt.Sprintf("%v:%v", row.AccountID, row.StockID)
+		tagMap[key] = append(tagMap[key], row.Tag)
+	}
+	for i := range snapshots {
+		snapshots[i].Tags = tagMap[snapshots[i].Key()]
+	}
+}
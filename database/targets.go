@@ -0,0 +1,86 @@
+package database
+
+import (
+	"github.com/goldsproutapp/goldsprout-backend/auth"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"gorm.io/gorm"
+)
+
+func GetVisibleTargets(db *gorm.DB, user models.User) ([]models.AllocationTarget, error) {
+	var targets []models.AllocationTarget
+	qry := db.Model(&models.AllocationTarget{})
+	if !user.IsAdmin {
+		qry = qry.Where("user_id IN ?", auth.GetAllowedUsers(user, true, false, false))
+	}
+	res := qry.Find(&targets)
+	return targets, res.Error
+}
+
+func GetTarget(db *gorm.DB, id uint) (models.AllocationTarget, error) {
+	var target models.AllocationTarget
+	res := db.Model(&models.AllocationTarget{}).Where("id = ?", id).First(&target)
+	return target, res.Error
+}
+
+// The users whose holdings are measured against the target.
+func GetTargetUsers(db *gorm.DB, target models.AllocationTarget) []uint {
+	if !target.Household {
+		return []uint{target.UserID}
+	}
+	return GetHouseholdUsers(db, target.UserID)
+}
+
+// The users whose holdings the given user can see.
+func GetHouseholdUsers(db *gorm.DB, userID uint) []uint {
+	var owner models.User
+	if !Exists(db.Model(&models.User{}).Where("id = ?", userID).Preload("AccessPermissions").First(&owner)) {
+		return []uint{userID}
+	}
+	return GetVisibleUserIDs(db, owner)
+}
+
+func DeleteTarget(db *gorm.DB, target models.AllocationTarget) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("allocation_target_id = ?", target.ID).Delete(&models.DriftAlert{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("allocation_target_id = ?", target.ID).Delete(&models.AllocationTargetEntry{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&target).Error
+	})
+}
+
+// Targets which may cover any of the given users: their own targets, and the household targets of
+// admins and of users who can read any of them. GetTargetUsers gives the exact set.
+func GetTargetsForUsers(db *gorm.DB, userIDs []uint) []models.AllocationTarget {
+	var targets []models.AllocationTarget
+	admins := db.Model(&models.User{}).Select("id").Where(&models.User{IsAdmin: true})
+	readers := db.Model(&models.AccessPermission{}).Select("user_id").
+		Where("access_for_id IN ?", userIDs).Where(&models.AccessPermission{Read: true})
+	db.Model(&models.AllocationTarget{}).
+		Where("user_id IN ?", userIDs).
+		Or("household = ? AND (user_id IN (?) OR user_id IN (?))", true, admins, readers).
+		Preload("User.AccessPermissions").
+		Find(&targets)
+	return targets
+}
+
+func GetVisibleAlerts(db *gorm.DB, user models.User, activeOnly bool) ([]models.DriftAlert, error) {
+	var alerts []models.DriftAlert
+	qry := db.Model(&models.DriftAlert{}).Where("dismissed = false").Order("created_at DESC")
+	if !user.IsAdmin {
+		qry = qry.Where("user_id IN ?", auth.GetAllowedUsers(user, true, false, false))
+	}
+	if activeOnly {
+		qry = qry.Where("active = true")
+	}
+	res := qry.Find(&alerts)
+	return alerts, res.Error
+}
+
+func GetAlert(db *gorm.DB, id uint) (models.DriftAlert, error) {
+	var alert models.DriftAlert
+	res := db.Model(&models.DriftAlert{}).Where("id = ?", id).First(&alert)
+	return alert, res.Error
+}
@@ -0,0 +1,30 @@
+package database
+
+import (
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"gorm.io/gorm"
+)
+
+func GetUserAPITokens(db *gorm.DB, userID uint) ([]models.APIToken, error) {
+	var tokens []models.APIToken
+	res := db.Model(&models.APIToken{}).Where("user_id = ?", userID).Order("created_at DESC").Find(&tokens)
+	return tokens, res.Error
+}
+
+func GetUserAPIToken(db *gorm.DB, userID uint, id uint) (models.APIToken, error) {
+	var token models.APIToken
+	res := db.Model(&models.APIToken{}).Where("id = ? AND user_id = ?", id, userID).First(&token)
+	return token, res.Error
+}
+
+func GetAPITokenUses(db *gorm.DB, tokenID uint, limit int) ([]models.APITokenUse, error) {
+	var uses []models.APITokenUse
+	res := db.Model(&models.APITokenUse{}).Where("api_token_id = ?", tokenID).Order("created_at DESC").Limit(limit).Find(&uses)
+	return uses, res.Error
+}
+
+func DeleteAPIToken(db *gorm.DB, token models.APIToken) {
+	db.Where("api_token_id = ?", token.ID).Delete(&models.APITokenUse{})
+	db.Where("api_token_id = ?", token.ID).Delete(&models.APITokenAccount{})
+	db.Delete(&token)
+}
@@ -84,3 +84,26 @@ func SendSetupInvitation(to string, token string) bool {
 	err := SendMessage(msg)
 	return err == nil
 }
+
+func SendPasswordReset(to string, token string, expiry string) bool {
+	msg := newMessage(to, "Reset your password")
+	url := fmt.Sprintf("%s/password/reset?t=%s", config.RequiredEnv(config.FRONTEND_BASE_URL), token)
+	msg.SetBodyHTMLTemplate(TemplateFile("password_reset"), map[string]string{
+		"ResetURL": url,
+		"Expiry":   expiry,
+	})
+	err := SendMessage(msg)
+	return err == nil
+}
+
+func SendDriftAlert(to string, target models.AllocationTarget, alerts []models.DriftAlert) bool {
+	msg := newMessage(to, "Your investments have drifted from their target allocation")
+	msg.SetBodyHTMLTemplate(TemplateFile("drift"), map[string]any{
+		"TargetName": target.Name,
+		"Dimension":  target.Dimension,
+		"Alerts":     alerts,
+		"URL":        fmt.Sprintf("%s/alerts", config.RequiredEnv(config.FRONTEND_BASE_URL)),
+	})
+	err := SendMessage(msg)
+	return err == nil
+}
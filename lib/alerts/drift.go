@@ -0,0 +1,68 @@
+package alerts
+
+import (
+	"slices"
+	"time"
+
+	"github.com/goldsproutapp/goldsprout-backend/calculations/rebalance"
+	"github.com/goldsproutapp/goldsprout-backend/database"
+	"github.com/goldsproutapp/goldsprout-backend/email"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"gorm.io/gorm"
+)
+
+// Re-evaluate every target covering any of the given users, raising an alert for each
+// category which has newly drifted outside of its tolerance and resolving alerts for
+// those which have returned within it.
+func EvaluateDrift(db *gorm.DB, userIDs []uint) {
+	for _, target := range database.GetTargetsForUsers(db, userIDs) {
+		targetUsers := database.GetTargetUsers(db, target)
+		if !slices.ContainsFunc(userIDs, func(uid uint) bool { return slices.Contains(targetUsers, uid) }) {
+			continue
+		}
+		filter := database.StockFilter{Users: targetUsers}
+		snapshots := database.GetFilteredSnapshots(db, target.User, filter, false)
+		newAlerts := evaluateTarget(db, target, rebalance.CalculateDrift(target, snapshots))
+		if len(newAlerts) > 0 && target.EmailAlerts {
+			email.SendDriftAlert(target.User.Email, target, newAlerts)
+		}
+	}
+}
+
+func evaluateTarget(db *gorm.DB, target models.AllocationTarget, drift []rebalance.CategoryDrift) []models.DriftAlert {
+	var active []models.DriftAlert
+	db.Model(&models.DriftAlert{}).
+		Where("allocation_target_id = ? AND active = true", target.ID).
+		Find(&active)
+	activeMap := map[string]models.DriftAlert{}
+	for _, alert := range active {
+		activeMap[alert.Category] = alert
+	}
+	newAlerts := []models.DriftAlert{}
+	now := time.Now()
+	for _, category := range drift {
+		existing, exists := activeMap[category.Category]
+		if category.OutOfTolerance && !exists {
+			newAlerts = append(newAlerts, models.DriftAlert{
+				AllocationTargetID: target.ID,
+				UserID:             target.UserID,
+				Category:           category.Category,
+				TargetPercentage:   category.TargetPercentage,
+				ActualPercentage:   category.ActualPercentage,
+				Tolerance:          target.Tolerance,
+				Active:             true,
+			})
+		} else if category.OutOfTolerance && exists {
+			existing.ActualPercentage = category.ActualPercentage
+			db.Save(&existing)
+		} else if exists {
+			existing.Active = false
+			existing.ResolvedAt = &now
+			db.Save(&existing)
+		}
+	}
+	if len(newAlerts) > 0 {
+		db.Create(&newAlerts)
+	}
+	return newAlerts
+}
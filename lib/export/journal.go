@@ -0,0 +1,280 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/goldsproutapp/goldsprout-backend/calculations/reports"
+	"github.com/goldsproutapp/goldsprout-backend/calculations/split"
+	"github.com/goldsproutapp/goldsprout-backend/constants"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	journalTransfersAccount   = "Equity:Transfers"
+	journalAdjustmentsAccount = "Equity:Adjustments"
+	journalIncomeAccount      = "Income:Investments"
+	journalFeesAccount        = "Expenses:Investments:Fees"
+)
+
+type journal struct {
+	w           *bufio.Writer
+	format      string
+	currency    string
+	accounts    map[string]string // StockSnapshot.Key() -> journal account
+	commodities map[uint]string   // StockID -> commodity
+	names       map[string]models.StockSnapshot
+}
+
+// An account name component: ASCII letters, digits and hyphens, starting with a capital or digit.
+func accountComponent(name string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range name {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			if hyphen && b.Len() > 0 {
+				b.WriteRune('-')
+			}
+			hyphen = false
+			b.WriteRune(r)
+		} else {
+			hyphen = true
+		}
+	}
+	out := b.String()
+	if out == "" {
+		return "Unknown"
+	}
+	return strings.ToUpper(out[:1]) + out[1:]
+}
+
+// A commodity symbol: capitals, digits and hyphens, starting with a letter and at most 24 characters.
+func commoditySymbol(code string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(code) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else if b.Len() > 0 {
+			b.WriteRune('-')
+		}
+	}
+	out := strings.TrimRight(b.String(), "-")
+	if out == "" || out[0] < 'A' {
+		out = "X" + out
+	}
+	if len(out) > 24 {
+		out = strings.TrimRight(out[:24], "-")
+	}
+	return out
+}
+
+func IsValidCommodity(symbol string) bool {
+	return symbol == commoditySymbol(symbol)
+}
+
+// Free text safe for use as a transaction narration in either format.
+func narration(text string) string {
+	return strings.NewReplacer(`"`, "'", ";", ",", "\n", " ").Replace(text)
+}
+
+func newJournal(w io.Writer, format string, currency string, snapshots []models.StockSnapshot) *journal {
+	j := &journal{
+		w:           bufio.NewWriter(w),
+		format:      format,
+		currency:    currency,
+		accounts:    map[string]string{},
+		commodities: map[uint]string{},
+		names:       map[string]models.StockSnapshot{},
+	}
+	used := map[string]uint{}
+	for _, snapshot := range snapshots {
+		j.names[snapshot.Key()] = snapshot
+		if _, ok := j.commodities[snapshot.StockID]; !ok {
+			symbol := commoditySymbol(snapshot.Stock.StockCode)
+			if _, taken := used[symbol]; taken {
+				suffix := fmt.Sprintf("-%d", snapshot.StockID)
+				if len(symbol)+len(suffix) > 24 {
+					symbol = strings.TrimRight(symbol[:24-len(suffix)], "-")
+				}
+				symbol += suffix
+			}
+			used[symbol] = snapshot.StockID
+			j.commodities[snapshot.StockID] = symbol
+		}
+		j.accounts[snapshot.Key()] = strings.Join([]string{
+			"Assets:Investments",
+			accountComponent(snapshot.User.Name()),
+			accountComponent(snapshot.Stock.Provider.Name),
+			accountComponent(snapshot.Account.Name),
+		}, ":")
+	}
+	return j
+}
+
+// hledger requires symbols containing anything other than letters to be quoted.
+func (j *journal) commodity(stockID uint) string {
+	symbol := j.commodities[stockID]
+	if j.format == constants.JOURNAL_FORMAT_HLEDGER && strings.IndexFunc(symbol, func(r rune) bool { return !unicode.IsLetter(r) }) != -1 {
+		return `"` + symbol + `"`
+	}
+	return symbol
+}
+
+func (j *journal) date(t time.Time) string {
+	return t.Format(constants.ISO8601)
+}
+
+func (j *journal) price(pence decimal.Decimal) string {
+	return pence.Div(decimal.NewFromInt(100)).String() + " " + j.currency
+}
+
+func (j *journal) header(t time.Time, flag string, description string) {
+	if j.format == constants.JOURNAL_FORMAT_BEANCOUNT {
+		fmt.Fprintf(j.w, "%s %s \"%s\"\n", j.date(t), flag, narration(description))
+	} else {
+		fmt.Fprintf(j.w, "%s %s %s\n", j.date(t), flag, narration(description))
+	}
+}
+
+func (j *journal) posting(account string, amount string) {
+	if amount == "" {
+		fmt.Fprintf(j.w, "  %s\n", account)
+	} else {
+		fmt.Fprintf(j.w, "  %-60s  %s\n", account, amount)
+	}
+}
+
+func (j *journal) open(t time.Time, accounts []string) {
+	for _, account := range accounts {
+		if j.format == constants.JOURNAL_FORMAT_BEANCOUNT {
+			fmt.Fprintf(j.w, "%s open %s\n", j.date(t), account)
+		} else {
+			fmt.Fprintf(j.w, "account %s\n", account)
+		}
+	}
+	fmt.Fprintln(j.w)
+}
+
+func (j *journal) prices(snapshots []models.StockSnapshot) {
+	seen := map[string]bool{}
+	for _, snapshot := range snapshots {
+		key := fmt.Sprintf("%d:%s", snapshot.StockID, j.date(snapshot.Date))
+		if seen[key] || snapshot.Price.IsZero() {
+			continue
+		}
+		seen[key] = true
+		if j.format == constants.JOURNAL_FORMAT_BEANCOUNT {
+			fmt.Fprintf(j.w, "%s price %s %s\n", j.date(snapshot.Date), j.commodity(snapshot.StockID), j.price(snapshot.Price))
+		} else {
+			fmt.Fprintf(j.w, "P %s %s %s\n", j.date(snapshot.Date), j.commodity(snapshot.StockID), j.price(snapshot.Price))
+		}
+	}
+	fmt.Fprintln(j.w)
+}
+
+func (j *journal) transaction(transaction reports.ReportTransaction) {
+	key := fmt.Sprintf("%v:%v", transaction.AccountID, transaction.StockID)
+	stock := j.names[key].Stock.Name
+	units := fmt.Sprintf("%s %s @ %s", transaction.Units.String(), j.commodity(transaction.StockID), j.price(transaction.Price))
+	if transaction.Attribution == constants.TransAttrIncomeFee {
+		if transaction.Value.IsPositive() {
+			j.header(transaction.Date, "*", "Income: "+stock)
+			j.posting(j.accounts[key], units)
+			j.posting(journalIncomeAccount, "")
+		} else {
+			j.header(transaction.Date, "*", "Fee: "+stock)
+			j.posting(j.accounts[key], units)
+			j.posting(journalFeesAccount, "")
+		}
+	} else {
+		if transaction.Value.IsPositive() {
+			j.header(transaction.Date, "*", "Buy: "+stock)
+		} else {
+			j.header(transaction.Date, "*", "Sell: "+stock)
+		}
+		j.posting(j.accounts[key], units)
+		j.posting(journalTransfersAccount, "")
+	}
+	fmt.Fprintln(j.w)
+}
+
+// Unit changes too small to be inferred as transactions are posted as a (flagged) adjustment, so that
+// the holdings balance as of date.
+func (j *journal) holdings(date time.Time, holdings []models.StockSnapshot, transactions []reports.ReportTransaction) {
+	units := map[string]decimal.Decimal{}
+	prices := map[string]decimal.Decimal{}
+	for _, transaction := range transactions {
+		key := fmt.Sprintf("%v:%v", transaction.AccountID, transaction.StockID)
+		units[key] = units[key].Sub(transaction.Units)
+		prices[key] = transaction.Price
+	}
+	for _, holding := range holdings {
+		units[holding.Key()] = units[holding.Key()].Add(holding.Units)
+		prices[holding.Key()] = holding.Price
+	}
+	keys := make([]string, 0, len(units))
+	for key := range units {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if units[key].IsZero() {
+			continue
+		}
+		stock := j.names[key]
+		j.header(date, "!", "Unrecorded unit changes: "+stock.Stock.Name)
+		j.posting(j.accounts[key], fmt.Sprintf("%s %s @ %s", units[key].String(), j.commodity(stock.StockID), j.price(prices[key])))
+		j.posting(journalAdjustmentsAccount, "")
+		fmt.Fprintln(j.w)
+	}
+
+	// Balances are asserted at the start of the day in Beancount, so the day after the last snapshot.
+	asserted := date.AddDate(0, 0, 1)
+	sort.Slice(holdings, func(i, k int) bool { return holdings[i].Key() < holdings[k].Key() })
+	if j.format == constants.JOURNAL_FORMAT_BEANCOUNT {
+		for _, holding := range holdings {
+			fmt.Fprintf(j.w, "%s balance %s %s %s\n", j.date(asserted), j.accounts[holding.Key()],
+				holding.Units.String(), j.commodity(holding.StockID))
+		}
+	} else if len(holdings) > 0 {
+		j.header(asserted, "*", "Holdings")
+		for _, holding := range holdings {
+			commodity := j.commodity(holding.StockID)
+			j.posting(j.accounts[holding.Key()], fmt.Sprintf("0 %s = %s %s", commodity, holding.Units.String(), commodity))
+		}
+	}
+}
+
+// Write the holdings and transactions as a Beancount or hledger journal. snapshots should be in date order
+// and transactions should be those inferred from them.
+func WriteJournal(w io.Writer, format string, currency string, snapshots []models.StockSnapshot, transactions []reports.ReportTransaction) error {
+	j := newJournal(w, format, currency, snapshots)
+	if len(snapshots) == 0 {
+		return j.w.Flush()
+	}
+	if format == constants.JOURNAL_FORMAT_BEANCOUNT {
+		fmt.Fprintf(j.w, "option \"operating_currency\" \"%s\"\n\n", currency)
+	}
+	accounts := []string{journalTransfersAccount, journalAdjustmentsAccount, journalIncomeAccount, journalFeesAccount}
+	seen := map[string]bool{}
+	for _, account := range j.accounts {
+		if !seen[account] {
+			seen[account] = true
+			accounts = append(accounts, account)
+		}
+	}
+	sort.Strings(accounts)
+	j.open(snapshots[0].Date, accounts)
+	j.prices(snapshots)
+	for _, transaction := range transactions {
+		j.transaction(transaction)
+	}
+	j.holdings(snapshots[len(snapshots)-1].Date, split.CurrentHoldings(snapshots), transactions)
+	return j.w.Flush()
+}
@@ -0,0 +1,133 @@
+package export
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/goldsproutapp/goldsprout-backend/calculations/reports"
+	"github.com/goldsproutapp/goldsprout-backend/constants"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	pdfFont       = "Helvetica"
+	pdfLineHeight = 6
+)
+
+type statementPDF struct {
+	*fpdf.Fpdf
+	tr func(string) string
+}
+
+func (p statementPDF) heading(text string) {
+	p.Ln(4)
+	p.SetFont(pdfFont, "B", 12)
+	p.CellFormat(0, pdfLineHeight+2, p.tr(text), "B", 1, "L", false, 0, "")
+	p.SetFont(pdfFont, "", 9)
+}
+
+// A table with the given column widths. The first leftAligned columns are left-aligned and the rest
+// right-aligned; text too wide for its column is truncated.
+func (p statementPDF) table(widths []float64, headings []string, rows [][]string, leftAligned int) {
+	p.SetFont(pdfFont, "B", 9)
+	for i, heading := range headings {
+		p.CellFormat(widths[i], pdfLineHeight, p.tr(heading), "B", 0, align(i, leftAligned), false, 0, "")
+	}
+	p.Ln(-1)
+	p.SetFont(pdfFont, "", 9)
+	for _, row := range rows {
+		for i, cell := range row {
+			p.CellFormat(widths[i], pdfLineHeight, p.fit(cell, widths[i]), "", 0, align(i, leftAligned), false, 0, "")
+		}
+		p.Ln(-1)
+	}
+}
+
+func (p statementPDF) fit(text string, width float64) string {
+	out := p.tr(text)
+	for len(out) > 0 && p.GetStringWidth(out) > width-1 {
+		out = out[:len(out)-1]
+	}
+	return out
+}
+
+func align(column int, leftAligned int) string {
+	if column < leftAligned {
+		return "L"
+	}
+	return "R"
+}
+
+func money(d decimal.Decimal) string {
+	return d.StringFixed(2)
+}
+
+func RenderStatementPDF(statement reports.Statement) ([]byte, error) {
+	pdf := statementPDF{Fpdf: fpdf.New("P", "mm", "A4", "")}
+	pdf.tr = pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetTitle(statement.Title, true)
+	pdf.AddPage()
+
+	pdf.SetFont(pdfFont, "B", 16)
+	pdf.CellFormat(0, 10, pdf.tr(statement.Title), "", 1, "L", false, 0, "")
+	pdf.SetFont(pdfFont, "", 10)
+	pdf.CellFormat(0, pdfLineHeight, pdf.tr(statement.Label+" ("+statement.Start.Format(constants.ISO8601)+
+		" to "+statement.End.AddDate(0, 0, -1).Format(constants.ISO8601)+")"), "", 1, "L", false, 0, "")
+
+	report := statement.Report
+	pdf.heading("Summary")
+	pdf.table([]float64{120, 60}, []string{"", "Value"}, [][]string{
+		{"Opening value", money(report.StartValue)},
+		{"Contributions", money(report.PurchaseTotal)},
+		{"Withdrawals", money(report.SellTotal)},
+		{"Gains", money(report.TotalGain)},
+		{"Income", money(report.TotalIncome)},
+		{"Fees paid", money(report.TotalFeePaid)},
+		{"Expected fees", money(report.ExpectedFees)},
+		{"Closing value", money(report.EndValue)},
+	}, 1)
+
+	pdf.heading("Allocation")
+	classes := make([]string, 0, len(statement.Allocation))
+	for class := range statement.Allocation {
+		classes = append(classes, class)
+	}
+	sort.Strings(classes)
+	allocation := make([][]string, len(classes))
+	for i, class := range classes {
+		allocation[i] = []string{class, statement.Allocation[class].StringFixed(2) + "%"}
+	}
+	pdf.table([]float64{120, 60}, []string{"Class", "Percentage"}, allocation, 1)
+
+	pdf.heading("Holdings")
+	holdings := make([][]string, len(statement.Holdings))
+	for i, holding := range statement.Holdings {
+		holdings[i] = []string{holding.Account, holding.Stock, holding.Units.String(), holding.Price.String(), money(holding.Value)}
+	}
+	pdf.table([]float64{40, 65, 25, 25, 25}, []string{"Account", "Stock", "Units", "Price", "Value"}, holdings, 2)
+
+	pdf.heading("Transactions")
+	transactions := make([][]string, len(statement.Transactions))
+	for i, transaction := range statement.Transactions {
+		kind := "Buy/sell"
+		if transaction.Attribution == constants.TransAttrIncomeFee {
+			kind = "Income/fee"
+		}
+		transactions[i] = []string{
+			transaction.Date.Format(constants.ISO8601),
+			transaction.Account,
+			transaction.Stock,
+			kind,
+			transaction.Units.String(),
+			money(transaction.Value),
+		}
+	}
+	pdf.table([]float64{22, 35, 58, 20, 20, 25}, []string{"Date", "Account", "Stock", "Type", "Units", "Value"}, transactions, 4)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,237 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/goldsproutapp/goldsprout-backend/calculations/reports"
+	"github.com/goldsproutapp/goldsprout-backend/calculations/split"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/shopspring/decimal"
+	"github.com/xuri/excelize/v2"
+)
+
+type Workbook struct {
+	file   *excelize.File
+	sheets int
+}
+
+func NewWorkbook() *Workbook {
+	return &Workbook{file: excelize.NewFile()}
+}
+
+// Cells are written with their Go types, so decimals should be converted with Number
+// and dates passed as time.Time for them to be typed in the workbook.
+func (w *Workbook) AddSheet(name string, headings []string, rows [][]any) error {
+	if w.sheets == 0 {
+		// A new file starts with a single default sheet.
+		if err := w.file.SetSheetName(w.file.GetSheetName(0), name); err != nil {
+			return err
+		}
+	} else if _, err := w.file.NewSheet(name); err != nil {
+		return err
+	}
+	w.sheets++
+	header := make([]any, len(headings))
+	for i, heading := range headings {
+		header[i] = heading
+	}
+	if err := w.file.SetSheetRow(name, "A1", &header); err != nil {
+		return err
+	}
+	for i, row := range rows {
+		cell, _ := excelize.CoordinatesToCellName(1, i+2)
+		if err := w.file.SetSheetRow(name, cell, &row); err != nil {
+			return err
+		}
+	}
+	return w.file.SetPanes(name, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"})
+}
+
+func (w *Workbook) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := w.file.Write(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func Number(d decimal.Decimal) float64 {
+	f, _ := d.Float64()
+	return f
+}
+
+func formatComposition(composition map[string]decimal.Decimal) string {
+	labels := make([]string, 0, len(composition))
+	for label := range composition {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	parts := make([]string, len(labels))
+	for i, label := range labels {
+		parts[i] = fmt.Sprintf("%s %s%%", label, composition[label].String())
+	}
+	return strings.Join(parts, "; ")
+}
+
+func compositionOrDefault(composition map[string]decimal.Decimal, headline string) map[string]decimal.Decimal {
+	if len(composition) == 0 {
+		return map[string]decimal.Decimal{headline: decimal.NewFromInt(100)}
+	}
+	return composition
+}
+
+var SnapshotHeadings = []string{
+	"Date", "User", "Provider", "Account", "Stock code", "Stock name", "Region", "Sector", "Annual fee",
+	"Units", "Price", "Cost", "Value", "Absolute change", "Normalised performance", "Transaction attribution",
+}
+
+func SnapshotRows(snapshots []models.StockSnapshot) [][]any {
+	rows := make([][]any, len(snapshots))
+	for i, snapshot := range snapshots {
+		rows[i] = []any{
+			snapshot.Date,
+			snapshot.User.Name(),
+			snapshot.Stock.Provider.Name,
+			snapshot.Account.Name,
+			snapshot.Stock.StockCode,
+			snapshot.Stock.Name,
+			snapshot.Stock.Region,
+			snapshot.Stock.Sector,
+			snapshot.Stock.AnnualFee,
+			Number(snapshot.Units),
+			Number(snapshot.Price),
+			Number(snapshot.Cost),
+			Number(snapshot.Value),
+			Number(snapshot.ChangeToDate),
+			Number(snapshot.NormalisedPerformance),
+			snapshot.TransactionAttribution,
+		}
+	}
+	return rows
+}
+
+var HoldingHeadings = []string{"As of", "User", "Account", "Stock code", "Stock name", "Units", "Price", "Cost", "Value"}
+
+func HoldingRows(snapshots []models.StockSnapshot) [][]any {
+	holdings := split.CurrentHoldings(snapshots)
+	sort.Slice(holdings, func(i, j int) bool {
+		return holdings[i].Key() < holdings[j].Key()
+	})
+	rows := make([][]any, len(holdings))
+	for i, holding := range holdings {
+		rows[i] = []any{
+			holding.Date,
+			holding.User.Name(),
+			holding.Account.Name,
+			holding.Stock.StockCode,
+			holding.Stock.Name,
+			Number(holding.Units),
+			Number(holding.Price),
+			Number(holding.Cost),
+			Number(holding.Value),
+		}
+	}
+	return rows
+}
+
+var AccountHeadings = []string{"ID", "User", "Provider", "Name", "Type"}
+
+// Accounts which appear in the snapshots. providers maps provider IDs to names.
+func AccountRows(snapshots []models.StockSnapshot, providers map[uint]string) [][]any {
+	seen := map[uint]bool{}
+	rows := [][]any{}
+	for _, snapshot := range snapshots {
+		if seen[snapshot.AccountID] {
+			continue
+		}
+		seen[snapshot.AccountID] = true
+		rows = append(rows, []any{
+			snapshot.AccountID,
+			snapshot.User.Name(),
+			providers[snapshot.Account.ProviderID],
+			snapshot.Account.Name,
+			snapshot.Account.Type,
+		})
+	}
+	return rows
+}
+
+var StockHeadings = []string{
+	"ID", "Provider", "Stock code", "Name", "Region", "Sector", "Annual fee",
+	"Class composition", "Region composition", "Sector composition",
+}
+
+// Stocks which appear in the snapshots.
+func StockRows(snapshots []models.StockSnapshot) [][]any {
+	seen := map[uint]bool{}
+	rows := [][]any{}
+	for _, snapshot := range snapshots {
+		if seen[snapshot.StockID] {
+			continue
+		}
+		seen[snapshot.StockID] = true
+		stock := snapshot.Stock
+		rows = append(rows, []any{
+			snapshot.StockID,
+			stock.Provider.Name,
+			stock.StockCode,
+			stock.Name,
+			stock.Region,
+			stock.Sector,
+			stock.AnnualFee,
+			formatComposition(stock.ClassCompositionMap),
+			formatComposition(compositionOrDefault(stock.RegionCompositionMap, stock.Region)),
+			formatComposition(compositionOrDefault(stock.SectorCompositionMap, stock.Sector)),
+		})
+	}
+	return rows
+}
+
+var ReportHeadings = []string{
+	"Period", "Start value", "End value", "Gross change", "Purchases", "Sales", "Net cashflow",
+	"Total gain", "Income", "Fees paid", "Expected fees", "Snapshots",
+}
+
+func ReportRows(periods []string, reportMap map[string]reports.Report) [][]any {
+	rows := make([][]any, len(periods))
+	for i, period := range periods {
+		report := reportMap[period]
+		rows[i] = []any{
+			period,
+			Number(report.StartValue),
+			Number(report.EndValue),
+			Number(report.GrossChange),
+			Number(report.PurchaseTotal),
+			Number(report.SellTotal),
+			Number(report.NetCashflow),
+			Number(report.TotalGain),
+			Number(report.TotalIncome),
+			Number(report.TotalFeePaid),
+			Number(report.ExpectedFees),
+			report.SnapshotCount,
+		}
+	}
+	return rows
+}
+
+var SplitHeadings = []string{"Property", "Category", "Percentage"}
+
+// Current split of the snapshots across each of the given properties.
+func SplitRows(snapshots []models.StockSnapshot, properties []string) [][]any {
+	rows := [][]any{}
+	for _, property := range properties {
+		result := split.CalculateSplit(split.CategoriseSnapshots(snapshots, property))
+		categories := make([]string, 0, len(result))
+		for category := range result {
+			categories = append(categories, category)
+		}
+		sort.Strings(categories)
+		for _, category := range categories {
+			rows = append(rows, []any{property, category, Number(result[category])})
+		}
+	}
+	return rows
+}
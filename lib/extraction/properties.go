@@ -1,6 +1,7 @@
 package extraction
 
 import (
+	"github.com/goldsproutapp/goldsprout-backend/constants"
 	"github.com/goldsproutapp/goldsprout-backend/models"
 	"github.com/goldsproutapp/goldsprout-backend/util"
 	"github.com/shopspring/decimal"
@@ -20,12 +21,6 @@ var singlePropGetters = map[string]func(models.StockSnapshot) string{
 	"account": func(snapshot models.StockSnapshot) string {
 		return snapshot.Account.Name
 	},
-	"sector": func(snapshot models.StockSnapshot) string {
-		return snapshot.Stock.Sector
-	},
-	"region": func(snapshot models.StockSnapshot) string {
-		return snapshot.Stock.Region
-	},
 	"stock": func(snapshot models.StockSnapshot) string {
 		return snapshot.Stock.Name
 	},
@@ -46,6 +41,30 @@ var multiPropGetters = map[string]func(models.StockSnapshot) []string{
 	"class": func(snapshot models.StockSnapshot) []string {
 		return util.MapKeys(snapshot.Stock.ClassCompositionMap)
 	},
+	"region": func(snapshot models.StockSnapshot) []string {
+		return compositionKeys(snapshot.Stock.RegionCompositionMap, snapshot.Stock.Region)
+	},
+	"sector": func(snapshot models.StockSnapshot) []string {
+		return compositionKeys(snapshot.Stock.SectorCompositionMap, snapshot.Stock.Sector)
+	},
+	"tag": func(snapshot models.StockSnapshot) []string {
+		if len(snapshot.Tags) == 0 {
+			return util.Only(constants.LABEL_UNTAGGED)
+		}
+		return snapshot.Tags
+	},
+}
+
+// Region and sector were single-valued before look-through composition was added,
+// so they remain valid anywhere a single property is. Tags are user-defined groupings
+// and are valid in the same places.
+var groupingProps = []string{"region", "sector", "tag"}
+
+func compositionKeys(composition map[string]decimal.Decimal, headline string) []string {
+	if len(composition) == 0 {
+		return util.Only(headline)
+	}
+	return util.MapKeys(composition)
 }
 
 func SnapshotCompositePropertyExtractionFunction(property string) func(models.StockSnapshot) []string {
@@ -75,6 +94,7 @@ func SplitSnapshotValueByPercentage(snapshot models.StockSnapshot, percentage de
 		ChangeSinceLast:        snapshot.ChangeSinceLast.Mul(pct),
 		NormalisedPerformance:  snapshot.NormalisedPerformance,
 		TransactionAttribution: snapshot.TransactionAttribution,
+		Tags:                   snapshot.Tags,
 	}
 }
 
@@ -84,6 +104,24 @@ var compositionSplitters = map[string]func(models.StockSnapshot, string) models.
 		s.Stock.ClassCompositionMap = map[string]decimal.Decimal{key: decimal.NewFromInt(100)}
 		return s
 	},
+	"region": func(snapshot models.StockSnapshot, key string) models.StockSnapshot {
+		s := SplitSnapshotValueByPercentage(snapshot, snapshot.Stock.RegionCompositionMap[key])
+		s.Stock.Region = key
+		s.Stock.RegionCompositionMap = map[string]decimal.Decimal{key: decimal.NewFromInt(100)}
+		return s
+	},
+	"sector": func(snapshot models.StockSnapshot, key string) models.StockSnapshot {
+		s := SplitSnapshotValueByPercentage(snapshot, snapshot.Stock.SectorCompositionMap[key])
+		s.Stock.Sector = key
+		s.Stock.SectorCompositionMap = map[string]decimal.Decimal{key: decimal.NewFromInt(100)}
+		return s
+	},
+	// A holding with several tags is apportioned equally between them, so that totals are preserved.
+	"tag": func(snapshot models.StockSnapshot, key string) models.StockSnapshot {
+		s := SplitSnapshotValueByPercentage(snapshot, decimal.NewFromInt(100).Div(decimal.NewFromInt(int64(len(snapshot.Tags)))))
+		s.Tags = util.Only(key)
+		return s
+	},
 }
 
 func GetKeysFromSnapshot(snapshot models.StockSnapshot, key string) []string {
@@ -110,6 +148,10 @@ func MultiTargets() []string {
 	return util.MapKeys(multiPropGetters)
 }
 
+func GroupingTargets() []string {
+	return groupingProps
+}
+
 func AllTargets() []string {
 	return append(SingleTargets(), MultiTargets()...)
 }
@@ -30,8 +30,11 @@ var timeFilterValidators = map[string]func(time.Time, time.Time) []string{
 	},
 }
 var timeListGetters = map[string]func([]string) []string{
-	"years":  times.ListYears,
-	"months": times.ListMonths,
+	"years":     times.ListYears,
+	"months":    times.ListMonths,
+	"quarters":  times.ListQuarters,
+	"weeks":     times.ListWeeks,
+	"tax_years": times.ListTaxYears,
 }
 
 func GetTimeListFunction(timeType string) func([]string) []string {
@@ -42,17 +45,22 @@ func ExtractTimeList(timeType string, times []string) []string {
 	return GetTimeListFunction(timeType)(times)
 }
 
-var timeFocus = map[string]func(string) []string{
-	"years":  times.FocusYear,
-	"months": times.FocusMonth,
+func timeFocus(yearStart times.YearStart) map[string]func(string) []string {
+	return map[string]func(string) []string{
+		"years":     times.FocusYear(yearStart),
+		"months":    times.FocusMonth,
+		"quarters":  times.FocusQuarter,
+		"weeks":     times.FocusWeek,
+		"tax_years": times.FocusTaxYear,
+	}
 }
 
-func GetTimeFocusFunction(timeType string) func(string) []string {
-	return timeFocus[timeType]
+func GetTimeFocusFunction(timeType string, yearStart times.YearStart) func(string) []string {
+	return timeFocus(yearStart)[timeType]
 }
 
-func GetTimeFocusKeys(timeType string, time string) []string {
-	return GetTimeFocusFunction(timeType)(time)
+func GetTimeFocusKeys(timeType string, yearStart times.YearStart, time string) []string {
+	return GetTimeFocusFunction(timeType, yearStart)(time)
 }
 
 
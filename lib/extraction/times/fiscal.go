@@ -0,0 +1,91 @@
+package times
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/goldsproutapp/goldsprout-backend/config"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+)
+
+// The day on which a (fiscal) year begins, eg. 6 April for the UK tax year.
+type YearStart struct {
+	Month time.Month
+	Day   int
+}
+
+var CalendarYear = YearStart{Month: time.January, Day: 1}
+
+// Parse MM-DD (or M-D).
+func ParseYearStart(input string) (YearStart, error) {
+	var month, day int
+	_, err := fmt.Sscanf(input, "%d-%d", &month, &day)
+	// Sscanf ignores anything after the day, so the whole input is checked.
+	exact := input == fmt.Sprintf("%02d-%02d", month, day) || input == fmt.Sprintf("%d-%d", month, day)
+	if err != nil || !exact || month < 1 || month > 12 || day < 1 || day > 28 {
+		// Limited to 28 so that every year has the date.
+		return YearStart{}, fmt.Errorf("invalid year start '%s'", input)
+	}
+	return YearStart{Month: time.Month(month), Day: day}, nil
+}
+
+func (y YearStart) String() string {
+	return fmt.Sprintf("%02d-%02d", y.Month, y.Day)
+}
+
+func (y YearStart) IsCalendarYear() bool {
+	return y == CalendarYear
+}
+
+// Start of the year containing t.
+func (y YearStart) For(t time.Time) time.Time {
+	start := time.Date(t.Year(), y.Month, y.Day, 0, 0, 0, 0, time.UTC)
+	if t.Before(start) {
+		start = start.AddDate(-1, 0, 0)
+	}
+	return start
+}
+
+// Calendar years are labelled "2024", others by the years they span, eg. "2024/25".
+func (y YearStart) Format(t time.Time) string {
+	start := y.For(t)
+	if y.IsCalendarYear() {
+		return fmt.Sprintf("%d", start.Year())
+	}
+	return fmt.Sprintf("%d/%02d", start.Year(), (start.Year()+1)%100)
+}
+
+// Parse a label produced by Format. The second year may also be given in full, eg. "2024/2025".
+func (y YearStart) Parse(label string) (time.Time, error) {
+	var year, end int
+	if y.IsCalendarYear() {
+		_, err := fmt.Sscanf(label, "%d", &year)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid year '%s'", label)
+		}
+	} else {
+		_, err := fmt.Sscanf(label, "%d/%d", &year, &end)
+		if err != nil || (end != year+1 && end != (year+1)%100) {
+			return time.Time{}, fmt.Errorf("invalid year '%s'", label)
+		}
+	}
+	return time.Date(year, y.Month, y.Day, 0, 0, 0, 0, time.UTC), nil
+}
+
+// Defaults to the UK tax year, which year-to-date figures used before the fiscal year was configurable.
+// Annual reports, yearly trends and FocusYear used calendar years, so FISCAL_YEAR_START=01-01 keeps those unchanged.
+func InstanceYearStart() YearStart {
+	yearStart, err := ParseYearStart(config.EnvOrDefault(config.ENVKEY_FISCAL_YEAR_START, TaxYear.String()))
+	if err != nil {
+		return TaxYear
+	}
+	return yearStart
+}
+
+func UserYearStart(user models.User) YearStart {
+	yearStart, err := ParseYearStart(user.FiscalYearStart)
+	if err != nil {
+		return InstanceYearStart()
+	}
+	return yearStart
+}
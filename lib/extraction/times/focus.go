@@ -3,17 +3,42 @@ package times
 import (
 	"strconv"
 	"time"
-
-	"github.com/goldsproutapp/goldsprout-backend/util"
 )
 
-func FocusYear(year string) []string {
-	yearNum := util.ParseIntOrDefault(year, -1)
-	start := time.Date(yearNum, time.January, 1, 0, 0, 0, 0, time.UTC)
-	end := time.Date(yearNum, time.December, 31, 23, 0, 0, 0, time.UTC)
+func focusRange(start time.Time, end time.Time) []string {
 	return []string{"months", strconv.FormatInt(start.Unix(), 10), strconv.FormatInt(end.Unix(), 10)}
 }
 
+func FocusYear(yearStart YearStart) func(string) []string {
+	return func(year string) []string {
+		start, err := yearStart.Parse(year)
+		if err != nil {
+			return []string{}
+		}
+		return focusRange(start, start.AddDate(1, 0, 0).Add(-time.Hour))
+	}
+}
+
 func FocusMonth(month string) []string {
 	return []string{}
 }
+
+func FocusQuarter(quarter string) []string {
+	start, err := ParseQuarter(quarter)
+	if err != nil {
+		return []string{}
+	}
+	return focusRange(start, start.AddDate(0, 3, 0).Add(-time.Hour))
+}
+
+func FocusWeek(week string) []string {
+	return []string{}
+}
+
+func FocusTaxYear(taxYear string) []string {
+	start, err := ParseTaxYear(taxYear)
+	if err != nil {
+		return []string{}
+	}
+	return focusRange(start, start.AddDate(1, 0, 0).Add(-time.Hour))
+}
@@ -8,8 +8,10 @@ func DateFormatter(fmt string) func(models.StockSnapshot) string {
 	}
 }
 
-func YearFormatter() func(models.StockSnapshot) string {
-	return DateFormatter("2006")
+func YearFormatter(yearStart YearStart) func(models.StockSnapshot) string {
+	return func(snapshot models.StockSnapshot) string {
+		return yearStart.Format(snapshot.Date)
+	}
 }
 
 func MonthNameFormatter() func(models.StockSnapshot) string {
@@ -17,5 +19,23 @@ func MonthNameFormatter() func(models.StockSnapshot) string {
 }
 
 func MonthYearFormatter() func(models.StockSnapshot) string {
-	return DateFormatter("")
+	return DateFormatter("January 2006")
+}
+
+func QuarterFormatter() func(models.StockSnapshot) string {
+	return func(snapshot models.StockSnapshot) string {
+		return FormatQuarter(snapshot.Date)
+	}
+}
+
+func WeekFormatter() func(models.StockSnapshot) string {
+	return func(snapshot models.StockSnapshot) string {
+		return FormatWeek(snapshot.Date)
+	}
+}
+
+func TaxYearFormatter() func(models.StockSnapshot) string {
+	return func(snapshot models.StockSnapshot) string {
+		return FormatTaxYear(snapshot.Date)
+	}
 }
@@ -2,6 +2,7 @@ package times
 
 import (
 	"sort"
+	"strings"
 
 	"github.com/goldsproutapp/goldsprout-backend/constants"
 	"github.com/goldsproutapp/goldsprout-backend/util"
@@ -9,13 +10,34 @@ import (
 
 func ListYears(years []string) []string {
 	sort.Slice(years, func(a, b int) bool {
-		errList := []error{}
-		return util.ParseUint(years[a], &errList) < util.ParseUint(years[b], &errList)
+		// Fiscal years are labelled eg. "2024/25", so only compare the leading year.
+		return leadingYear(years[a]) < leadingYear(years[b])
 	})
 	return years
 }
 
+func leadingYear(label string) int {
+	year, _, _ := strings.Cut(label, "/")
+	return util.ParseIntOrDefault(year, 0)
+}
+
 func ListMonths(months []string) []string {
 	return constants.MONTHS
 }
 
+// Quarter, week and tax year labels all lead with the year and are zero-padded,
+// so lexical order is chronological order.
+func ListQuarters(quarters []string) []string {
+	sort.Strings(quarters)
+	return quarters
+}
+
+func ListWeeks(weeks []string) []string {
+	sort.Strings(weeks)
+	return weeks
+}
+
+func ListTaxYears(taxYears []string) []string {
+	sort.Strings(taxYears)
+	return taxYears
+}
@@ -0,0 +1,61 @@
+package times
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/goldsproutapp/goldsprout-backend/constants"
+)
+
+var TaxYear = YearStart{Month: constants.TAX_YEAR_START_MONTH, Day: constants.TAX_YEAR_START_DAY}
+
+func Quarter(t time.Time) int {
+	return (int(t.Month())-1)/3 + 1
+}
+
+func QuarterStart(t time.Time) time.Time {
+	return time.Date(t.Year(), time.Month((Quarter(t)-1)*3+1), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func DayStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func MonthStart(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func WeekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7 // weeks start on Monday (ISO 8601)
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, time.UTC)
+}
+
+func TaxYearStart(t time.Time) time.Time {
+	return TaxYear.For(t)
+}
+
+func FormatQuarter(t time.Time) string {
+	return fmt.Sprintf("%d Q%d", t.Year(), Quarter(t))
+}
+
+func FormatWeek(t time.Time) string {
+	year, week := t.ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
+
+func FormatTaxYear(t time.Time) string {
+	return TaxYear.Format(t)
+}
+
+func ParseQuarter(label string) (time.Time, error) {
+	var year, quarter int
+	_, err := fmt.Sscanf(label, "%d Q%d", &year, &quarter)
+	if err != nil || quarter < 1 || quarter > 4 {
+		return time.Time{}, fmt.Errorf("invalid quarter '%s'", label)
+	}
+	return time.Date(year, time.Month((quarter-1)*3+1), 1, 0, 0, 0, 0, time.UTC), nil
+}
+
+func ParseTaxYear(label string) (time.Time, error) {
+	return TaxYear.Parse(label)
+}
@@ -4,16 +4,22 @@ import "github.com/goldsproutapp/goldsprout-backend/models"
 
 type TimeExtractionSet = map[string]func(models.StockSnapshot) string
 
-func PerformanceTimeExtractionSet() TimeExtractionSet {
+func PerformanceTimeExtractionSet(yearStart YearStart) TimeExtractionSet {
 	return TimeExtractionSet{
-		"years":  YearFormatter(),
-		"months": MonthNameFormatter(),
+		"years":     YearFormatter(yearStart),
+		"months":    MonthNameFormatter(),
+		"quarters":  QuarterFormatter(),
+		"weeks":     WeekFormatter(),
+		"tax_years": TaxYearFormatter(),
 	}
 }
 
-func ReportExtractionSet() TimeExtractionSet {
+func ReportExtractionSet(yearStart YearStart) TimeExtractionSet {
 	return TimeExtractionSet{
-		"annual":  YearFormatter(),
-		"monthly": MonthYearFormatter(),
+		"annual":    YearFormatter(yearStart),
+		"monthly":   MonthYearFormatter(),
+		"quarterly": QuarterFormatter(),
+		"weekly":    WeekFormatter(),
+		"tax_year":  TaxYearFormatter(),
 	}
 }
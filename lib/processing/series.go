@@ -0,0 +1,127 @@
+package processing
+
+import (
+	"sort"
+	"time"
+
+	"github.com/goldsproutapp/goldsprout-backend/lib/extraction/times"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/shopspring/decimal"
+)
+
+type resolution struct {
+	start func(time.Time) time.Time
+	next  func(time.Time) time.Time
+}
+
+var resolutions = map[string]resolution{
+	"daily": {
+		start: times.DayStart,
+		next:  func(t time.Time) time.Time { return t.AddDate(0, 0, 1) },
+	},
+	"weekly": {
+		start: times.WeekStart,
+		next:  func(t time.Time) time.Time { return t.AddDate(0, 0, 7) },
+	},
+	"monthly": {
+		start: times.MonthStart,
+		next:  func(t time.Time) time.Time { return t.AddDate(0, 1, 0) },
+	},
+}
+
+func IsValidResolution(name string) bool {
+	_, ok := resolutions[name]
+	return ok
+}
+
+func sortByDate(snapshots []models.StockSnapshot) {
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].Date.Before(snapshots[j].Date)
+	})
+}
+
+// Group snapshots by account, each sorted by date.
+func GroupByAccount(snapshots []models.StockSnapshot) map[uint][]models.StockSnapshot {
+	groups := map[uint][]models.StockSnapshot{}
+	for _, snapshot := range snapshots {
+		groups[snapshot.AccountID] = append(groups[snapshot.AccountID], snapshot)
+	}
+	for _, group := range groups {
+		sortByDate(group)
+	}
+	return groups
+}
+
+// The most recent snapshot batch of each account on or before the given date.
+func HoldingsAt(groups map[uint][]models.StockSnapshot, date time.Time) []models.StockSnapshot {
+	out := []models.StockSnapshot{}
+	for _, snapshots := range groups {
+		i := sort.Search(len(snapshots), func(i int) bool {
+			return snapshots[i].Date.After(date)
+		})
+		if i == 0 {
+			continue
+		}
+		batchDate := snapshots[i-1].Date
+		for j := i - 1; j >= 0 && snapshots[j].Date.Equal(batchDate); j-- {
+			out = append(out, snapshots[j])
+		}
+	}
+	return out
+}
+
+// Revalue a carried-forward snapshot at a later price.
+func revalue(snapshot models.StockSnapshot, price models.StockSnapshot) models.StockSnapshot {
+	value := snapshot.Units.Mul(price.Price).Div(decimal.NewFromInt(100))
+	snapshot.ChangeToDate = snapshot.ChangeToDate.Add(value.Sub(snapshot.Value))
+	snapshot.Value = value
+	snapshot.Price = price.Price
+	return snapshot
+}
+
+// Build a regular time series keyed by the start of each period, from the first snapshot until now.
+// Each point holds every account's last known holdings as at the end of the period, revalued
+// using the most recent price in prices (snapshots of the same stocks, possibly in other accounts).
+func CreateRegularSnapshotMap(snapshots []models.StockSnapshot, prices []models.StockSnapshot, resolutionName string, now time.Time) map[time.Time][]models.StockSnapshot {
+	out := map[time.Time][]models.StockSnapshot{}
+	res, ok := resolutions[resolutionName]
+	if !ok || len(snapshots) == 0 {
+		return out
+	}
+	groups := GroupByAccount(snapshots)
+	priceSeries := map[uint][]models.StockSnapshot{}
+	for _, list := range [][]models.StockSnapshot{prices, snapshots} {
+		for _, price := range list {
+			if price.Price.IsPositive() {
+				priceSeries[price.StockID] = append(priceSeries[price.StockID], price)
+			}
+		}
+	}
+	for _, series := range priceSeries {
+		sortByDate(series)
+	}
+	first := snapshots[0].Date
+	for _, snapshot := range snapshots {
+		if snapshot.Date.Before(first) {
+			first = snapshot.Date
+		}
+	}
+	for start := res.start(first); !start.After(now); start = res.next(start) {
+		cutoff := res.next(start).Add(-time.Nanosecond)
+		if cutoff.After(now) {
+			cutoff = now
+		}
+		holdings := HoldingsAt(groups, cutoff)
+		for i, holding := range holdings {
+			series := priceSeries[holding.StockID]
+			j := sort.Search(len(series), func(j int) bool {
+				return series[j].Date.After(cutoff)
+			})
+			if j > 0 && series[j-1].Date.After(holding.Date) {
+				holdings[i] = revalue(holding, series[j-1])
+			}
+		}
+		out[start] = holdings
+	}
+	return out
+}
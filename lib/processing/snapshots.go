@@ -3,21 +3,17 @@ package processing
 import (
 	"time"
 
+	"github.com/goldsproutapp/goldsprout-backend/lib/extraction/times"
 	"github.com/goldsproutapp/goldsprout-backend/models"
 	"github.com/goldsproutapp/goldsprout-backend/util"
 )
 
-func CreateMergedSnapshotMap(snapshots []models.StockSnapshot) (map[time.Time][]models.StockSnapshot, map[string][]models.StockSnapshot) {
+func CreateMergedSnapshotMap(snapshots []models.StockSnapshot, fiscalYear times.YearStart) (map[time.Time][]models.StockSnapshot, map[string][]models.StockSnapshot) {
 
 	yearStartMap := map[string][]models.StockSnapshot{}
 	latestMap := map[string]models.StockSnapshot{}
 	snapshotMap := map[uint]map[time.Time][]models.StockSnapshot{}
-	now := time.Now()
-	// TODO: this should be configurable for l10n
-	yearStart := time.Date(now.Year(), 4, 6, 0, 0, 0, 0, time.UTC)
-	if now.Month() < 4 || now.Month() == 4 && now.Day() < 6 {
-		yearStart = yearStart.AddDate(-1, 0, 0)
-	}
+	yearStart := fiscalYear.For(time.Now())
 	for _, snapshot := range snapshots {
 		key := snapshot.Key()
 		if !util.ContainsKey(latestMap, key) ||
@@ -74,3 +70,14 @@ func CreateMergedSnapshotMap(snapshots []models.StockSnapshot) (map[time.Time][]
 	return snapshotMapMerged, yearStartMap
 }
 
+// Snapshots since the start of the current fiscal year, by holding.
+func CreateYearStartMap(snapshots []models.StockSnapshot, fiscalYear times.YearStart) map[string][]models.StockSnapshot {
+	yearStartMap := map[string][]models.StockSnapshot{}
+	yearStart := fiscalYear.For(time.Now())
+	for _, snapshot := range snapshots {
+		if snapshot.Date.After(yearStart) {
+			yearStartMap[snapshot.Key()] = append(yearStartMap[snapshot.Key()], snapshot)
+		}
+	}
+	return yearStartMap
+}
@@ -0,0 +1,94 @@
+package statements
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/goldsproutapp/goldsprout-backend/calculations/reports"
+	"github.com/goldsproutapp/goldsprout-backend/constants"
+	"github.com/goldsproutapp/goldsprout-backend/database"
+	"github.com/goldsproutapp/goldsprout-backend/lib/export"
+	"github.com/goldsproutapp/goldsprout-backend/lib/extraction/times"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/goldsproutapp/goldsprout-backend/util"
+	"gorm.io/gorm"
+)
+
+const archiveInterval = time.Hour
+
+// Render the statement for owner (or their household) for the period containing date,
+// as visible to viewer. The statement is not saved.
+func Generate(db *gorm.DB, viewer models.User, owner models.User, household bool, period string, date time.Time) (models.Statement, error) {
+	users := []uint{owner.ID}
+	title := fmt.Sprintf("Statement for %s", owner.Name())
+	if household {
+		users = database.GetHouseholdUsers(db, owner.ID)
+		title = fmt.Sprintf("Household statement for %s", owner.Name())
+	}
+	fiscalYear := times.UserYearStart(owner)
+	start, end := reports.StatementPeriod(period, fiscalYear, date)
+	filter := database.StockFilter{Users: users, UpperDate: end}
+	snapshots := database.GetFilteredSnapshots(db, viewer, filter, false)
+	statement := reports.BuildStatement(title, period, fiscalYear, date, snapshots)
+	content, err := export.RenderStatementPDF(statement)
+	if err != nil {
+		return models.Statement{}, err
+	}
+	return models.Statement{
+		UserID:    owner.ID,
+		Household: household,
+		Period:    period,
+		Label:     statement.Label,
+		Start:     start,
+		End:       end,
+		Content:   content,
+	}, nil
+}
+
+// Archive a statement for every user, and every household of more than one user, for each
+// period which has completed since the last archived one. Periods before a user's first snapshot are skipped.
+func ArchiveCompleted(db *gorm.DB, now time.Time) {
+	for _, user := range database.GetAllUsers(db, "AccessPermissions") {
+		var first models.StockSnapshot
+		if !database.Exists(db.Model(&models.StockSnapshot{}).Where("user_id = ?", user.ID).Order("date").First(&first)) {
+			continue
+		}
+		modes := []bool{false}
+		if len(database.GetHouseholdUsers(db, user.ID)) > 1 {
+			modes = append(modes, true)
+		}
+		fiscalYear := times.UserYearStart(user)
+		for _, period := range constants.STATEMENT_PERIODS {
+			_, complete := reports.LastCompletePeriod(period, fiscalYear, now)
+			for _, household := range modes {
+				start, _ := reports.StatementPeriod(period, fiscalYear, first.Date)
+				if last, ok := database.LastStatementEnd(db, user.ID, household, period); ok && last.After(start) {
+					start = last
+				}
+				for start.Before(complete) {
+					periodStart, end := reports.StatementPeriod(period, fiscalYear, start)
+					start = end
+					if database.StatementExists(db, user.ID, household, period, periodStart) {
+						continue
+					}
+					statement, err := Generate(db, user, user, household, period, periodStart)
+					if err != nil {
+						fmt.Println("Error generating statement: " + err.Error())
+						continue
+					}
+					db.Create(&statement)
+				}
+			}
+		}
+	}
+}
+
+// Periodically archive completed statements. This does not return.
+func RunArchiveSchedule(db *gorm.DB) {
+	for {
+		util.Recovered("statement archive", func() {
+			ArchiveCompleted(db, time.Now())
+		})
+		time.Sleep(archiveInterval)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/goldsproutapp/goldsprout-backend/config"
 	"github.com/goldsproutapp/goldsprout-backend/database"
+	"github.com/goldsproutapp/goldsprout-backend/lib/statements"
 	"github.com/goldsproutapp/goldsprout-backend/middleware"
 	"github.com/goldsproutapp/goldsprout-backend/routes"
 )
@@ -22,6 +23,9 @@ func main() {
 	if config.DemoModeEnabled() {
 		database.CreateDemoAccount(db)
 	}
+	if config.StatementArchiveEnabled() {
+		go statements.RunArchiveSchedule(db)
+	}
 
 	router := gin.Default()
 	router.Use(middleware.CORSMiddleware())
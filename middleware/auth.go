@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/goldsproutapp/goldsprout-backend/auth"
@@ -17,6 +18,25 @@ import (
 
 const CtxUserInfoKey = "UserInfo"
 const CtxSessionKey = "SessionInfo"
+const CtxAPITokenKey = "APITokenInfo"
+const CtxTokenScopeKey = "TokenScope"
+const CtxTokenAccountsKey = "TokenAccountsChecked"
+
+// Allow API tokens with the given scope to make write requests to the route. This must come
+// before Authenticate.
+func PermitTokenScope(scope string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set(CtxTokenScopeKey, scope)
+	}
+}
+
+// Allow API tokens which are restricted to certain accounts to use the route. The handler must
+// check each account with TokenAllowsAccount. This must come before Authenticate.
+func PermitRestrictedTokens() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set(CtxTokenAccountsKey, true)
+	}
+}
 
 func Authenticate(preload ...string) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -39,6 +59,29 @@ func Authenticate(preload ...string) gin.HandlerFunc {
 				Client:        util.FormatUA(ctx.Request.UserAgent()),
 				IsDemoSession: true,
 			}
+		} else if strings.HasPrefix(token, "Bearer "+constants.API_TOKEN_PREFIX) {
+			apiToken, err := auth.AuthenticateAPIToken(db, strings.TrimPrefix(token, "Bearer "))
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Invalid authentication provided."})
+				return
+			}
+			if !auth.TokenScopeAllows(apiToken.Scope, ctx.Request.Method, ctx.GetString(CtxTokenScopeKey)) ||
+				(len(apiToken.Accounts) > 0 && !ctx.GetBool(CtxTokenAccountsKey)) {
+				auth.LogAPITokenUse(db, apiToken, ctx.Request.Method, ctx.Request.URL.Path, ctx.ClientIP(), http.StatusForbidden)
+				request.Forbidden(ctx)
+				return
+			}
+			session = models.Session{
+				UserID:         apiToken.UserID,
+				Client:         apiToken.Name,
+				IsTokenSession: true,
+			}
+			user, err = auth.UserForSession(db, session, preload...)
+			if err != nil {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Invalid authentication provided."})
+				return
+			}
+			ctx.Set(CtxAPITokenKey, apiToken)
 		} else {
 			var hasPrefix bool
 			token, hasPrefix = strings.CutPrefix(token, "Bearer ")
@@ -48,6 +91,13 @@ func Authenticate(preload ...string) gin.HandlerFunc {
 				ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Invalid authentication provided."})
 				return
 			}
+			now := time.Now()
+			if auth.SessionExpired(session, now) {
+				db.Delete(&session)
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Session expired."})
+				return
+			}
+			auth.TouchSession(db, &session, now)
 			user, err = auth.UserForSession(db, session, preload...)
 			if err != nil || !hasPrefix {
 				ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Invalid authentication provided."})
@@ -58,6 +108,9 @@ func Authenticate(preload ...string) gin.HandlerFunc {
 		ctx.Set(CtxUserInfoKey, user)
 		ctx.Set(CtxSessionKey, session)
 		ctx.Next()
+		if val, ok := ctx.Get(CtxAPITokenKey); ok {
+			auth.LogAPITokenUse(db, val.(models.APIToken), ctx.Request.Method, ctx.Request.URL.Path, ctx.ClientIP(), ctx.Writer.Status())
+		}
 	}
 }
 
@@ -76,3 +129,13 @@ func GetSession(ctx *gin.Context) models.Session {
 	}
 	return models.Session{}
 }
+
+// Whether the request may write to the account. Only API tokens can be restricted to certain accounts.
+func TokenAllowsAccount(ctx *gin.Context, accountID uint) bool {
+	val, exists := ctx.Get(CtxAPITokenKey)
+	if !exists {
+		return true
+	}
+	token, ok := val.(models.APIToken)
+	return ok && auth.TokenAllowsAccount(token, accountID)
+}
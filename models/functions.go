@@ -1,8 +1,29 @@
 package models
 
-import "fmt"
+import (
+	"fmt"
+	"slices"
+
+	"github.com/goldsproutapp/goldsprout-backend/constants"
+	"github.com/shopspring/decimal"
+)
 
 func (s *StockSnapshot) Key() string {
 	return fmt.Sprintf("%v:%v", s.AccountID, s.StockID)
 }
 
+func (a Account) IsTaxSheltered() bool {
+	return slices.Contains(constants.TAX_SHELTERED_ACCOUNT_TYPES, a.Type)
+}
+
+func (a Asset) IsLiability() bool {
+	return slices.Contains(constants.LIABILITY_ASSET_TYPES, a.Type)
+}
+
+// The balance with the sign appropriate to the asset type.
+func (a Asset) SignedValue(value decimal.Decimal) decimal.Decimal {
+	if a.IsLiability() {
+		return value.Abs().Neg()
+	}
+	return value.Abs()
+}
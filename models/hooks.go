@@ -1,6 +1,9 @@
 package models
 
 import (
+	"fmt"
+	"strconv"
+
 	"github.com/goldsproutapp/goldsprout-backend/constants"
 	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
@@ -19,6 +22,38 @@ func (s *Stock) BeforeSave(tx *gorm.DB) error {
 	return nil
 }
 
+func (s *Stock) AfterSave(tx *gorm.DB) error {
+	saveComposition(tx, s.ID, constants.COMPOSITION_REGION, s.RegionCompositionMap, s.Region)
+	saveComposition(tx, s.ID, constants.COMPOSITION_SECTOR, s.SectorCompositionMap, s.Sector)
+	return nil
+}
+
+// A nil map leaves the stored composition untouched; the maps are only set when loaded with
+// database.AttachCompositions or supplied by a client. A single entry for the stock's own
+// region/sector is the default, so is not stored.
+func saveComposition(tx *gorm.DB, stockID uint, dimension string, composition map[string]decimal.Decimal, headline string) {
+	if composition == nil {
+		return
+	}
+	objs := []CompositionEntry{}
+	labels := []string{}
+	_, isHeadline := composition[headline]
+	if len(composition) != 1 || !isHeadline {
+		for k, v := range composition {
+			objs = append(objs, CompositionEntry{StockID: stockID, Dimension: dimension, Label: k, Percentage: v})
+			labels = append(labels, k)
+		}
+	}
+	if len(objs) > 0 {
+		tx.Save(&objs)
+	}
+	qry := tx.Where("stock_id = ? AND dimension = ?", stockID, dimension)
+	if len(labels) > 0 {
+		qry = qry.Where("label NOT IN ?", labels)
+	}
+	qry.Delete(&CompositionEntry{})
+}
+
 func (s *Stock) AfterFind(tx *gorm.DB) error {
 	s.ClassCompositionMap = map[string]decimal.Decimal{}
 	if len(s.classCompositionObjects) == 0 { // we're wasting a query if it has been fetched but there are no entries.
@@ -34,9 +69,119 @@ func (s *Stock) AfterFind(tx *gorm.DB) error {
 	return nil
 }
 
+// Set the look-through composition from the stored entries. Without any, the maps are left nil,
+// so that saving the stock doesn't store a composition which was never supplied.
+func (s *Stock) SetComposition(entries []CompositionEntry) {
+	s.RegionCompositionMap = nil
+	s.SectorCompositionMap = nil
+	for _, obj := range entries {
+		if obj.Dimension == constants.COMPOSITION_REGION {
+			if s.RegionCompositionMap == nil {
+				s.RegionCompositionMap = map[string]decimal.Decimal{}
+			}
+			s.RegionCompositionMap[obj.Label] = obj.Percentage
+		} else if obj.Dimension == constants.COMPOSITION_SECTOR {
+			if s.SectorCompositionMap == nil {
+				s.SectorCompositionMap = map[string]decimal.Decimal{}
+			}
+			s.SectorCompositionMap[obj.Label] = obj.Percentage
+		}
+	}
+}
+
 func (u *UserStock) AfterFind(tx *gorm.DB) error {
 	if u.Stock.ID != 0 {
 		u.Stock.AfterFind(tx)
 	}
+	u.Tags = []string{}
+	tx.Model(&UserStockTag{}).Where("user_stock_id = ?", u.ID).Order("tag").Pluck("tag", &(u.Tags))
+	return nil
+}
+
+func (t *AllocationTarget) AfterSave(tx *gorm.DB) error {
+	objs := []AllocationTargetEntry{}
+	categories := []string{}
+	for k, v := range t.Targets {
+		objs = append(objs, AllocationTargetEntry{AllocationTargetID: t.ID, Category: k, Percentage: v})
+		categories = append(categories, k)
+	}
+	if len(objs) > 0 {
+		tx.Save(&objs)
+	}
+	qry := tx.Where("allocation_target_id = ?", t.ID)
+	if len(categories) > 0 {
+		qry = qry.Where("category NOT IN ?", categories)
+	}
+	qry.Delete(&AllocationTargetEntry{})
+	return nil
+}
+
+func (g *Goal) AfterSave(tx *gorm.DB) error {
+	objs := []GoalLink{}
+	for _, id := range g.Accounts {
+		objs = append(objs, GoalLink{GoalID: g.ID, Kind: constants.GOAL_LINK_ACCOUNT, Value: fmt.Sprint(id)})
+	}
+	for _, id := range g.Holdings {
+		objs = append(objs, GoalLink{GoalID: g.ID, Kind: constants.GOAL_LINK_HOLDING, Value: fmt.Sprint(id)})
+	}
+	for _, tag := range g.Tags {
+		objs = append(objs, GoalLink{GoalID: g.ID, Kind: constants.GOAL_LINK_TAG, Value: tag})
+	}
+	tx.Where("goal_id = ?", g.ID).Delete(&GoalLink{})
+	if len(objs) > 0 {
+		tx.Create(&objs)
+	}
+	return nil
+}
+
+func (g *Goal) AfterFind(tx *gorm.DB) error {
+	g.Accounts = []uint{}
+	g.Holdings = []uint{}
+	g.Tags = []string{}
+	tx.Model(&GoalLink{}).Where("goal_id = ?", g.ID).Find(&(g.links))
+	for _, obj := range g.links {
+		switch obj.Kind {
+		case constants.GOAL_LINK_ACCOUNT:
+			if id, err := strconv.ParseUint(obj.Value, 10, 0); err == nil {
+				g.Accounts = append(g.Accounts, uint(id))
+			}
+		case constants.GOAL_LINK_HOLDING:
+			if id, err := strconv.ParseUint(obj.Value, 10, 0); err == nil {
+				g.Holdings = append(g.Holdings, uint(id))
+			}
+		case constants.GOAL_LINK_TAG:
+			g.Tags = append(g.Tags, obj.Value)
+		}
+	}
+	return nil
+}
+
+func (t *APIToken) AfterSave(tx *gorm.DB) error {
+	objs := []APITokenAccount{}
+	for _, id := range t.Accounts {
+		objs = append(objs, APITokenAccount{APITokenID: t.ID, AccountID: id})
+	}
+	tx.Where("api_token_id = ?", t.ID).Delete(&APITokenAccount{})
+	if len(objs) > 0 {
+		tx.Create(&objs)
+	}
+	return nil
+}
+
+func (t *APIToken) AfterFind(tx *gorm.DB) error {
+	t.Accounts = []uint{}
+	tx.Model(&APITokenAccount{}).Where("api_token_id = ?", t.ID).Find(&(t.accounts))
+	for _, obj := range t.accounts {
+		t.Accounts = append(t.Accounts, obj.AccountID)
+	}
+	return nil
+}
+
+func (t *AllocationTarget) AfterFind(tx *gorm.DB) error {
+	t.Targets = map[string]decimal.Decimal{}
+	tx.Model(&AllocationTargetEntry{}).Where("allocation_target_id = ?", t.ID).Find(&(t.entries))
+	for _, obj := range t.entries {
+		t.Targets[obj.Category] = obj.Percentage
+	}
 	return nil
 }
@@ -20,6 +20,7 @@ type Account struct {
 	ProviderID uint     `json:"provider_id,omitempty"`
 	User       User     `json:"-"`
 	UserID     uint     `json:"user_id,omitempty"`
+	Type       string   `json:"type,omitempty" gorm:"default:GENERAL"` // GENERAL | ISA | PENSION
 }
 
 type Stock struct {
@@ -35,6 +36,10 @@ type Stock struct {
 	AnnualFee               float32  `json:"annual_fee,omitempty"`
 	classCompositionObjects []ClassCompositionEntry
 	ClassCompositionMap     map[string]decimal.Decimal `json:"class_composition" gorm:"-" json:"-" sql:"-"`
+	// Look-through breakdown for multi-region or multi-sector funds, where loaded.
+	// If nil, the stock is wholly in its own region/sector.
+	RegionCompositionMap map[string]decimal.Decimal `json:"region_composition" gorm:"-"`
+	SectorCompositionMap map[string]decimal.Decimal `json:"sector_composition" gorm:"-"`
 }
 
 type ClassCompositionEntry struct {
@@ -44,15 +49,29 @@ type ClassCompositionEntry struct {
 	Percentage decimal.Decimal
 }
 
+type CompositionEntry struct {
+	StockID    uint `gorm:"primaryKey;autoIncrement:false"`
+	Stock      Stock
+	Dimension  string `gorm:"primaryKey;autoIncrement:false"` // region | sector
+	Label      string `gorm:"primaryKey;autoIncrement:false"`
+	Percentage decimal.Decimal
+}
+
 type UserStock struct {
-	ID            uint    `json:"id,omitempty"`
-	UserID        uint    `json:"user_id,omitempty"`
-	Stock         Stock   `json:"stock,omitempty"`
-	StockID       uint    `json:"stock_id,omitempty"`
-	Account       Account `json:"account,omitempty"`
-	AccountID     uint    `json:"account_id,omitempty"`
-	CurrentlyHeld bool    `json:"currently_held,omitempty"`
-	Notes         string  `json:"notes,omitempty"`
+	ID            uint     `json:"id,omitempty"`
+	UserID        uint     `json:"user_id,omitempty"`
+	Stock         Stock    `json:"stock,omitempty"`
+	StockID       uint     `json:"stock_id,omitempty"`
+	Account       Account  `json:"account,omitempty"`
+	AccountID     uint     `json:"account_id,omitempty"`
+	CurrentlyHeld bool     `json:"currently_held,omitempty"`
+	Notes         string   `json:"notes,omitempty"`
+	Tags          []string `json:"tags" gorm:"-"`
+}
+
+type UserStockTag struct {
+	UserStockID uint   `gorm:"primaryKey;autoIncrement:false"`
+	Tag         string `gorm:"primaryKey;autoIncrement:false;size:64"`
 }
 
 type StockSnapshot struct {
@@ -72,6 +91,7 @@ type StockSnapshot struct {
 	ChangeSinceLast        decimal.Decimal `json:"change_since_last,omitempty"`      // absolute change in value
 	NormalisedPerformance  decimal.Decimal `json:"normalised_performance,omitempty"` // relative change in price per unit (normalised for 30 days)
 	TransactionAttribution uint            `json:"transaction_attribution" gorm:"default:0"`
+	Tags                   []string        `json:"tags,omitempty" gorm:"-"` // Tags of the corresponding UserStock, where loaded.
 }
 
 type RegularTransaction struct {
@@ -103,3 +123,110 @@ type AccessPermission struct {
 	Write       bool `json:"write,omitempty"`
 	Limited     bool `json:"limited,omitempty"`
 }
+
+// Desired split of holdings across the categories of a single dimension (eg. region).
+type AllocationTarget struct {
+	ID        uint   `json:"id,omitempty"`
+	UserID    uint   `json:"user_id,omitempty"`
+	User      User   `json:"-"`
+	Name      string `json:"name,omitempty"`
+	Dimension string `json:"dimension,omitempty"`
+	Household bool   `json:"household"` // Include holdings of all users the owner can see.
+	entries   []AllocationTargetEntry
+	Targets   map[string]decimal.Decimal `json:"targets" gorm:"-"`
+	// Percentage points a category may drift from its target before an alert is raised.
+	Tolerance   decimal.Decimal `json:"tolerance"`
+	EmailAlerts bool            `json:"email_alerts"`
+}
+
+type DriftAlert struct {
+	ID                 uint             `json:"id,omitempty"`
+	AllocationTarget   AllocationTarget `json:"-"`
+	AllocationTargetID uint             `json:"allocation_target_id,omitempty"`
+	UserID             uint             `json:"user_id,omitempty"`
+	Category           string           `json:"category,omitempty"`
+	TargetPercentage   decimal.Decimal  `json:"target_percentage"`
+	ActualPercentage   decimal.Decimal  `json:"actual_percentage"`
+	Tolerance          decimal.Decimal  `json:"tolerance"`
+	Active             bool             `json:"active"` // Cleared once the category is back within tolerance.
+	Dismissed          bool             `json:"dismissed"`
+	CreatedAt          time.Time        `json:"created_at"`
+	ResolvedAt         *time.Time       `json:"resolved_at,omitempty"`
+}
+
+// A non-investment asset or liability, tracked by balance only.
+type Asset struct {
+	ID         uint             `json:"id,omitempty"`
+	UserID     uint             `json:"user_id,omitempty"`
+	User       User             `json:"-"`
+	Name       string           `json:"name,omitempty"`
+	Type       string           `json:"type,omitempty"`
+	Notes      string           `json:"notes,omitempty"`
+	Valuations []AssetValuation `json:"valuations,omitempty"`
+}
+
+type AssetValuation struct {
+	ID      uint            `json:"id,omitempty"`
+	AssetID uint            `json:"asset_id,omitempty"`
+	Date    time.Time       `json:"date"`
+	Value   decimal.Decimal `json:"value"` // negative for liabilities
+}
+
+type Scenario struct {
+	ID     uint            `json:"id,omitempty"`
+	UserID uint            `json:"user_id,omitempty"`
+	User   User            `json:"-"`
+	Name   string          `json:"name,omitempty"`
+	Shocks []ScenarioShock `json:"shocks"`
+}
+
+type ScenarioShock struct {
+	ID         uint            `json:"-"`
+	ScenarioID uint            `json:"-"`
+	Dimension  string          `json:"dimension"`
+	Category   string          `json:"category"`
+	Change     decimal.Decimal `json:"change"` // percentage
+}
+
+type Goal struct {
+	ID           uint            `json:"id,omitempty"`
+	UserID       uint            `json:"user_id,omitempty"`
+	User         User            `json:"-"`
+	Name         string          `json:"name,omitempty"`
+	TargetAmount decimal.Decimal `json:"target_amount"`
+	TargetDate   time.Time       `json:"target_date"`
+	Household    bool            `json:"household"` // Include holdings of all users the owner can see.
+	links        []GoalLink
+	// Holdings counted towards the goal. If all are empty, every holding is counted.
+	Accounts []uint   `json:"accounts" gorm:"-"`
+	Holdings []uint   `json:"holdings" gorm:"-"` // UserStock IDs
+	Tags     []string `json:"tags" gorm:"-"`
+}
+
+type GoalLink struct {
+	GoalID uint `gorm:"primaryKey;autoIncrement:false"`
+	Goal   Goal
+	Kind   string `gorm:"primaryKey;autoIncrement:false;size:16"`
+	Value  string `gorm:"primaryKey;autoIncrement:false;size:64"`
+}
+
+// A rendered statement, archived so that it can be downloaded later.
+type Statement struct {
+	ID        uint      `json:"id,omitempty"`
+	UserID    uint      `json:"user_id,omitempty"`
+	User      User      `json:"-"`
+	Household bool      `json:"household"`
+	Period    string    `json:"period,omitempty"`
+	Label     string    `json:"label,omitempty"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Content   []byte    `json:"-" gorm:"type:longblob"` // PDF
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type AllocationTargetEntry struct {
+	AllocationTargetID uint `gorm:"primaryKey;autoIncrement:false"`
+	AllocationTarget   AllocationTarget
+	Category           string `gorm:"primaryKey;autoIncrement:false"`
+	Percentage         decimal.Decimal
+}
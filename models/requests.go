@@ -1,5 +1,7 @@
 package models
 
+import "github.com/shopspring/decimal"
+
 type StockSnapshotCreationPayload struct {
 	StockName              string `binding:"required" json:"stock_name"`
 	StockCode              string `binding:"required" json:"stock_code"`
@@ -38,6 +40,7 @@ type StockFilterQuery struct {
 	FilterProviders    string `json:"filter_providers,omitempty" form:"filter_providers"`
 	FilterUsers        string `json:"filter_users,omitempty" form:"filter_users"`
 	FilterAccounts     string `json:"filter_accounts,omitempty" form:"filter_accounts"`
+	FilterTags         string `json:"filter_tags,omitempty" form:"filter_tags"`
 	FilterIgnoreBefore string `json:"filter_ignore_before,omitempty" form:"filter_ignore_before"`
 	FilterIgnoreAfter  string `json:"filter_ignore_after,omitempty" form:"filter_ignore_after"`
 }
@@ -67,6 +70,39 @@ type ReportRequestQuery struct {
 	Period           string `json:"period,omitempty" form:"period" binding:"required"`
 }
 
+// The date range is given by the filter's ignore before/after bounds.
+type ExportCSVRequestQuery struct {
+	StockFilterQuery
+	Columns string `json:"columns,omitempty" form:"columns"` // comma-separated; defaults to every column
+	Latest  bool   `json:"latest,omitempty" form:"latest"`   // only the current holdings
+}
+
+type ExportJournalRequestQuery struct {
+	StockFilterQuery
+	Format   string `json:"format,omitempty" form:"format" binding:"required"` // beancount | hledger
+	Currency string `json:"currency,omitempty" form:"currency"`
+}
+
+type ExportRequestQuery struct {
+	StockFilterQuery
+	Period string `json:"period,omitempty" form:"period"` // report period for the workbook; defaults to annual
+}
+
+type StatementRequestQuery struct {
+	Period    string `json:"period,omitempty" form:"period" binding:"required"`
+	Date      int64  `json:"date,omitempty" form:"date"` // any time within the period; defaults to the last complete period
+	User      uint   `json:"user,omitempty" form:"user"` // defaults to the current user
+	Household bool   `json:"household,omitempty" form:"household"`
+}
+
+type ReportComparisonRequestQuery struct {
+	ReportRequestQuery
+	Current  string `json:"current,omitempty" form:"current"`   // period label; defaults to the latest period
+	Previous string `json:"previous,omitempty" form:"previous"` // period label; defaults according to against
+	Against  string `json:"against,omitempty" form:"against"`   // previous (default) or last_year
+	By       string `json:"by,omitempty" form:"by"`             // optional category breakdown
+}
+
 type UserInvitationRequest struct {
 	Email     string `binding:"required" json:"email,omitempty"`
 	FirstName string `binding:"required" json:"first_name,omitempty"`
@@ -82,6 +118,44 @@ type PasswordChangeRequest struct {
 	NewPassword string `binding:"required" json:"new_password,omitempty"`
 }
 
+type PasswordForgottenRequest struct {
+	Email string `binding:"required" json:"email,omitempty"`
+}
+
+type PasswordResetRequest struct {
+	Token    string `binding:"required" json:"token,omitempty"`
+	Password string `binding:"required" json:"password,omitempty"`
+}
+
+type APITokenRequest struct {
+	Name      string `binding:"required" json:"name,omitempty"`
+	Scope     string `binding:"required" json:"scope,omitempty"`
+	ExpiresAt int64  `json:"expires_at,omitempty"` // unix timestamp; never expires if not given
+	Accounts  []uint `json:"accounts,omitempty"`
+}
+
+type OIDCCallbackRequest struct {
+	Code  string `binding:"required" json:"code,omitempty"`
+	State string `binding:"required" json:"state,omitempty"`
+}
+
+type TwoFactorLoginRequest struct {
+	Challenge string `binding:"required" json:"challenge,omitempty"`
+	Code      string `binding:"required" json:"code,omitempty"` // TOTP or recovery code
+}
+
+type TwoFactorChallengeRequest struct {
+	Challenge string `binding:"required" json:"challenge,omitempty"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `binding:"required" json:"code,omitempty"`
+}
+
+type InstanceSettingsRequest struct {
+	RequireAdminTwoFactor *bool `binding:"required" json:"require_admin_two_factor,omitempty"`
+}
+
 type SetPermissionsRequest struct {
 	User        uint                        `binding:"required" json:"user,omitempty"`
 	Permissions []SetPermissionsRequestItem `binding:"required" json:"permissions,omitempty"`
@@ -103,8 +177,100 @@ type StockMergeRequest struct {
 	Stock     uint `binding:"required" json:"stock,omitempty"`
 }
 
+type SetTagsRequest struct {
+	Tags []string `binding:"required" json:"tags"`
+}
+
 type CreateAccountRequest struct {
 	Name       string `binding:"required" json:"name,omitempty"`
 	UserID     uint   `binding:"required" json:"user_id,omitempty"`
 	ProviderID uint   `binding:"required" json:"provider_id,omitempty"`
+	Type       string `json:"type,omitempty"`
+}
+
+type UpdateAccountRequest struct {
+	Name string `binding:"required" json:"name,omitempty"`
+	Type string `binding:"required" json:"type,omitempty"`
+}
+
+type AllocationTargetRequest struct {
+	Name      string                     `binding:"required" json:"name,omitempty"`
+	UserID    uint                       `binding:"required" json:"user_id,omitempty"`
+	Dimension string                     `binding:"required" json:"dimension,omitempty"`
+	Household bool                       `json:"household"`
+	Targets   map[string]decimal.Decimal `binding:"required" json:"targets,omitempty"`
+
+	Tolerance   *decimal.Decimal `json:"tolerance"` // defaults to constants.DEFAULT_DRIFT_TOLERANCE
+	EmailAlerts bool             `json:"email_alerts"`
+}
+
+type AssetRequest struct {
+	UserID uint   `binding:"required" json:"user_id,omitempty"`
+	Name   string `binding:"required" json:"name,omitempty"`
+	Type   string `binding:"required" json:"type,omitempty"`
+	Notes  string `json:"notes,omitempty"`
+}
+
+type AssetValuationRequest struct {
+	Date  int64           `binding:"required" json:"date"`
+	Value decimal.Decimal `binding:"required" json:"value"`
+}
+
+type ScenarioRequest struct {
+	UserID uint            `binding:"required" json:"user_id,omitempty"`
+	Name   string          `binding:"required" json:"name,omitempty"`
+	Shocks []ScenarioShock `binding:"required" json:"shocks"`
+}
+
+type RunScenarioRequest struct {
+	Shocks []ScenarioShock `binding:"required" json:"shocks"`
+}
+
+type ReturnAssumption struct {
+	Return     float64 `json:"return"`     // expected annual return, percentage
+	Volatility float64 `json:"volatility"` // annual standard deviation, percentage
+}
+
+type SimulationRequest struct {
+	CurrentAge          int                         `binding:"required" json:"current_age"`
+	EndAge              int                         `binding:"required" json:"end_age"`
+	WithdrawalAge       int                         `binding:"required" json:"withdrawal_age"`
+	MonthlyContribution decimal.Decimal             `json:"monthly_contribution"` // until the withdrawal age
+	AnnualWithdrawal    decimal.Decimal             `json:"annual_withdrawal"`    // from the withdrawal age
+	Assumptions         map[string]ReturnAssumption `json:"assumptions"`          // by class
+	DefaultAssumption   ReturnAssumption            `json:"default_assumption"`   // for classes without an assumption
+	Paths               int                         `json:"paths"`
+	Seed                int64                       `json:"seed"` // zero for a random seed
+}
+
+type GoalRequest struct {
+	Name         string          `binding:"required" json:"name,omitempty"`
+	UserID       uint            `binding:"required" json:"user_id,omitempty"`
+	TargetAmount decimal.Decimal `binding:"required" json:"target_amount"`
+	TargetDate   int64           `binding:"required" json:"target_date"`
+	Household    bool            `json:"household"`
+	Accounts     []uint          `json:"accounts"`
+	Holdings     []uint          `json:"holdings"`
+	Tags         []string        `json:"tags"`
+}
+
+type RebalanceRequestQuery struct {
+	Target            uint   `binding:"required" json:"target,omitempty" form:"target"`
+	Contribution      string `json:"contribution,omitempty" form:"contribution"`
+	ContributionsOnly bool   `json:"contributions_only" form:"contributions_only"`
+}
+
+type PortfolioPerformanceRequestQuery struct {
+	StockFilterQuery
+	Resolution string `json:"resolution,omitempty" form:"resolution"` // daily, weekly or monthly; omit for snapshot dates only
+}
+
+type RollingReturnsRequestQuery struct {
+	StockFilterQuery
+	By string `json:"by,omitempty" form:"by"` // any extraction property; defaults to the whole portfolio
+}
+
+type CapitalGainsRequestQuery struct {
+	StockFilterQuery
+	Method string `binding:"required" json:"method,omitempty" form:"method"`
 }
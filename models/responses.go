@@ -12,12 +12,26 @@ type OverviewResponseUserEntry struct {
 	NumProviders  int             `json:"num_providers,omitempty"`
 	NumStocks     int             `json:"num_stocks,omitempty"`
 	LastSnapshot  time.Time       `json:"last_snapshot,omitempty"`
+	Assets        decimal.Decimal `json:"assets,omitempty"`
+	Liabilities   decimal.Decimal `json:"liabilities,omitempty"` // negative
+	NetWorth      decimal.Decimal `json:"net_worth,omitempty"`
+}
+
+type NetWorthPoint struct {
+	Date        time.Time       `json:"date"`
+	Investments decimal.Decimal `json:"investments"`
+	Assets      decimal.Decimal `json:"assets"`
+	Liabilities decimal.Decimal `json:"liabilities"`
+	NetWorth    decimal.Decimal `json:"net_worth"`
 }
 
 type OverviewResponse struct {
 	OverviewResponseUserEntry
 	Users map[string]OverviewResponseUserEntry `json:"users,omitempty"`
 	AUM   decimal.Decimal                      `json:"aum,omitempty"`
+
+	NetWorth        decimal.Decimal `json:"net_worth,omitempty"`
+	NetWorthHistory []NetWorthPoint `json:"net_worth_history,omitempty"`
 }
 
 type AccountReponse struct {
@@ -37,3 +51,11 @@ func (i HoldingInfo) Merge(other HoldingInfo) HoldingInfo {
 		Units: i.Units.Add(other.Units),
 	}
 }
+
+type SessionResponse struct {
+	ID         uint      `json:"id"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	Client     string    `json:"client"`
+	Current    bool      `json:"current"`
+}
@@ -18,18 +18,106 @@ type User struct {
 	AccessPermissions   []AccessPermission   `gorm:"foreignKey:UserID" json:"access_permissions"`
 	InvitationToken     string               `json:"-"`
 	Active              bool                 `json:"active"`
-	ClientOpts          string               `json:"client_options"` // Likely for colour scheme etc. but the client can do whatever with this.
+	ClientOpts          string               `json:"client_options"`    // Likely for colour scheme etc. but the client can do whatever with this.
+	FiscalYearStart     string               `json:"fiscal_year_start"` // MM-DD, or empty to use the instance default.
+	TOTPSecret          string               `json:"-"`                 // Set during enrolment, before TOTPEnabled.
+	TOTPEnabled         bool                 `json:"totp_enabled"`
+	TOTPLastStep        int64                `json:"-"` // The last time step used, so that codes cannot be replayed.
+	TwoFactorFailures   int                  `json:"-"` // Failed codes since the last success, or since the lockout expired.
+	TwoFactorFailedAt   *time.Time           `json:"-"`
 	CreatedAt           time.Time            `json:"created_at"`
 }
 
 type Session struct {
-	ID            uint      `json:"id,omitempty"`
-	User          User      `json:"user,omitempty"`
-	UserID        uint      `json:"user_id,omitempty"`
-	TokenHash     string    `json:"token_hash,omitempty"`
-	CreatedAt     time.Time `json:"created_at,omitempty"`
-	Client        string    `json:"client,omitempty"`
-	IsDemoSession bool      `json:"is_demo_session,omitempty" gorm:"-"`
+	ID             uint      `json:"id,omitempty"`
+	User           User      `json:"user,omitempty"`
+	UserID         uint      `json:"user_id,omitempty"`
+	TokenHash      string    `json:"-"`
+	CreatedAt      time.Time `json:"created_at,omitempty"`
+	LastUsedAt     time.Time `json:"last_used_at,omitempty"`
+	Client         string    `json:"client,omitempty"`
+	IsDemoSession  bool      `json:"is_demo_session,omitempty" gorm:"-"`
+	IsTokenSession bool      `json:"is_token_session,omitempty" gorm:"-"` // Authenticated with an APIToken.
+}
+
+// A named token for automation, with a limited scope.
+type APIToken struct {
+	ID         uint       `json:"id,omitempty"`
+	UserID     uint       `json:"user_id,omitempty"`
+	User       User       `json:"-"`
+	Name       string     `json:"name,omitempty"`
+	TokenHash  string     `json:"-"`
+	Scope      string     `json:"scope,omitempty"` // read | snapshot_write | admin
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	UseCount   uint       `json:"use_count"`
+	CreatedAt  time.Time  `json:"created_at"`
+	accounts   []APITokenAccount
+	// If not empty, the token can only be used to write snapshots to these accounts.
+	Accounts []uint `json:"accounts" gorm:"-"`
+}
+
+// A request made with an APIToken.
+type APITokenUse struct {
+	ID         uint      `json:"id,omitempty"`
+	APITokenID uint      `json:"api_token_id,omitempty"`
+	APIToken   APIToken  `json:"-"`
+	Method     string    `json:"method,omitempty"`
+	Path       string    `json:"path,omitempty"`
+	ClientIP   string    `json:"client_ip,omitempty"`
+	Status     int       `json:"status"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type APITokenAccount struct {
+	APITokenID uint `gorm:"primaryKey;autoIncrement:false"`
+	APIToken   APIToken
+	AccountID  uint `gorm:"primaryKey;autoIncrement:false"`
+}
+
+type RecoveryCode struct {
+	ID       uint `gorm:"primaryKey"`
+	User     User
+	UserID   uint
+	CodeHash string
+	UsedAt   *time.Time
+}
+
+// Issued after a successful password login for a user with two-factor authentication,
+// in exchange for a session token once a code has been given.
+type LoginChallenge struct {
+	ID        uint `gorm:"primaryKey"`
+	User      User
+	UserID    uint
+	TokenHash string
+	Client    string
+	Enrol     bool // The user must enrol before the login can complete.
+	Attempts  int
+	ExpiresAt time.Time
+}
+
+type PasswordResetToken struct {
+	ID        uint `gorm:"primaryKey"`
+	User      User
+	UserID    uint
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+}
+
+// A pending OIDC login, between redirecting to the identity provider and its callback.
+type OIDCLoginState struct {
+	ID        uint `gorm:"primaryKey"`
+	StateHash string
+	Nonce     string
+	Verifier  string // PKCE code verifier
+	ExpiresAt time.Time
+}
+
+// Instance-wide settings, which are stored in a single row.
+type InstanceSettings struct {
+	ID                    uint `json:"-" gorm:"primaryKey"`
+	RequireAdminTwoFactor bool `json:"require_admin_two_factor"`
 }
 
 func (u User) Name() string {
@@ -39,6 +127,9 @@ func (u User) Name() string {
 func (u *User) ApplyUpdate(update UserUpdateInfo) {
 	u.FirstName = update.FirstName
 	u.LastName = update.LastName
+	if update.FiscalYearStart != nil {
+		u.FiscalYearStart = *update.FiscalYearStart
+	}
 }
 
 func (u User) PublicInfo() PublicUserInfo {
@@ -62,4 +153,6 @@ type PublicUserInfo struct {
 type UserUpdateInfo struct {
 	FirstName string `binding:"required" json:"first_name,omitempty"`
 	LastName  string `binding:"required" json:"last_name,omitempty"`
+
+	FiscalYearStart *string `json:"fiscal_year_start,omitempty"` // left unchanged if not given; empty to use the instance default.
 }
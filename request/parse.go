@@ -16,6 +16,7 @@ func BuildStockFilter(query models.StockFilterQuery) database.StockFilter {
 		Providers: util.UintArray(query.FilterProviders),
 		Users:     util.UintArray(query.FilterUsers),
 		Accounts:  util.Split(query.FilterAccounts, ","),
+		Tags:      util.Split(query.FilterTags, ","),
 		LowerDate: ignoreBefore,
 		UpperDate: ignoreAfter,
 	}
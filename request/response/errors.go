@@ -20,3 +20,7 @@ func Conflict(ctx *gin.Context) {
 func NotFound(ctx *gin.Context) {
 	ctx.AbortWithStatusJSON(http.StatusNotFound, gin.H{"success": false, "message": "not found"})
 }
+
+func InternalServerError(ctx *gin.Context) {
+	ctx.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"success": false, "message": "internal server error"})
+}
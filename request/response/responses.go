@@ -2,6 +2,7 @@ package response
 
 import (
 	"fmt"
+	"io"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -24,3 +25,16 @@ func FileOK(ctx *gin.Context, filename string, content string) {
 	ctx.Header("Content-Type", "text/plain")
 	ctx.Writer.WriteString(content)
 }
+
+func BinaryFileOK(ctx *gin.Context, filename string, contentType string, content []byte) {
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	ctx.Data(http.StatusOK, contentType, content)
+}
+
+// Headers are sent before write is called, so an error part way through cannot be reported.
+func StreamFileOK(ctx *gin.Context, filename string, contentType string, write func(io.Writer) error) error {
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%s", filename))
+	ctx.Header("Content-Type", contentType)
+	ctx.Status(http.StatusOK)
+	return write(ctx.Writer)
+}
@@ -1,8 +1,11 @@
 package routes
 
 import (
+	"slices"
+
 	"github.com/gin-gonic/gin"
 	"github.com/goldsproutapp/goldsprout-backend/auth"
+	"github.com/goldsproutapp/goldsprout-backend/constants"
 	"github.com/goldsproutapp/goldsprout-backend/database"
 	"github.com/goldsproutapp/goldsprout-backend/middleware"
 	"github.com/goldsproutapp/goldsprout-backend/models"
@@ -50,6 +53,11 @@ func CreateAccount(ctx *gin.Context) {
 		response.BadRequest(ctx)
 		return
 	}
+	accountType := util.UpdateIfSet(constants.ACCOUNT_TYPE_GENERAL, body.Type)
+	if !slices.Contains(constants.ACCOUNT_TYPES, accountType) {
+		response.BadRequest(ctx)
+		return
+	}
 	db := middleware.GetDB(ctx)
 	user := middleware.GetUser(ctx)
 	if !auth.HasAccessPerm(user, body.UserID, false, true, false) {
@@ -60,6 +68,7 @@ func CreateAccount(ctx *gin.Context) {
 		Name:       body.Name,
 		ProviderID: body.ProviderID,
 		UserID:     body.UserID,
+		Type:       accountType,
 	}
 	res := db.Create(&account)
 	if res.Error != nil {
@@ -69,6 +78,35 @@ func CreateAccount(ctx *gin.Context) {
 	response.Created(ctx, account)
 }
 
+func UpdateAccount(ctx *gin.Context) {
+	errs := []error{}
+	id := util.ParseUint(ctx.Param("id"), &errs)
+	if len(errs) > 0 {
+		response.BadRequest(ctx)
+		return
+	}
+	var body models.UpdateAccountRequest
+	if ctx.BindJSON(&body) != nil || !slices.Contains(constants.ACCOUNT_TYPES, body.Type) {
+		response.BadRequest(ctx)
+		return
+	}
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	account, err := database.GetAccount(db, id)
+	if err != nil {
+		response.NotFound(ctx)
+		return
+	}
+	if !auth.HasAccessPerm(user, account.UserID, false, true, false) {
+		response.Forbidden(ctx)
+		return
+	}
+	account.Name = body.Name
+	account.Type = body.Type
+	db.Save(&account)
+	response.OK(ctx, account)
+}
+
 func DeleteAccount(ctx *gin.Context) {
 	errs := []error{}
 	id := util.ParseUint(ctx.Param("id"), &errs)
@@ -96,5 +134,6 @@ func DeleteAccount(ctx *gin.Context) {
 func RegisterAccountRoutes(router *gin.RouterGroup) {
 	router.GET("/accounts", middleware.Authenticate("AccessPermissions"), GetAccounts)
 	router.POST("/accounts", middleware.Authenticate("AccessPermissions"), CreateAccount)
+	router.PATCH("/accounts/:id", middleware.Authenticate("AccessPermissions"), UpdateAccount)
 	router.DELETE("/accounts/:id", middleware.Authenticate("AccessPermissions"), DeleteAccount)
 }
@@ -124,8 +124,37 @@ func MassDelete(ctx *gin.Context) {
 	}
 }
 
+func GetInstanceSettings(ctx *gin.Context) {
+	user := middleware.GetUser(ctx)
+	if !user.IsAdmin {
+		response.Forbidden(ctx)
+		return
+	}
+	response.OK(ctx, auth.GetInstanceSettings(middleware.GetDB(ctx)))
+}
+
+func UpdateInstanceSettings(ctx *gin.Context) {
+	user := middleware.GetUser(ctx)
+	if !user.IsAdmin {
+		response.Forbidden(ctx)
+		return
+	}
+	var body models.InstanceSettingsRequest
+	if ctx.BindJSON(&body) != nil {
+		response.BadRequest(ctx)
+		return
+	}
+	db := middleware.GetDB(ctx)
+	settings := auth.GetInstanceSettings(db)
+	settings.RequireAdminTwoFactor = *body.RequireAdminTwoFactor
+	db.Save(&settings)
+	response.OK(ctx, settings)
+}
+
 func RegisterAdminRoutes(router *gin.RouterGroup) {
 	router.POST("/invite", middleware.Authenticate(), InviteUser)
 	router.PUT("/permissions", middleware.Authenticate(), SetPermissions)
 	router.POST("/massdelete", middleware.Authenticate(), MassDelete)
+	router.GET("/settings", middleware.Authenticate(), GetInstanceSettings)
+	router.PUT("/settings", middleware.Authenticate(), UpdateInstanceSettings)
 }
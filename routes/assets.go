@@ -0,0 +1,167 @@
+package routes
+
+import (
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goldsproutapp/goldsprout-backend/auth"
+	"github.com/goldsproutapp/goldsprout-backend/constants"
+	"github.com/goldsproutapp/goldsprout-backend/database"
+	"github.com/goldsproutapp/goldsprout-backend/middleware"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/goldsproutapp/goldsprout-backend/request/response"
+	"github.com/goldsproutapp/goldsprout-backend/util"
+)
+
+func GetAssets(ctx *gin.Context) {
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	assets, err := database.GetAssets(db, database.GetVisibleUserIDs(db, user))
+	if err != nil {
+		response.BadRequest(ctx)
+		return
+	}
+	response.OK(ctx, assets)
+}
+
+func CreateAsset(ctx *gin.Context) {
+	var body models.AssetRequest
+	if ctx.BindJSON(&body) != nil || !slices.Contains(constants.ASSET_TYPES, body.Type) {
+		response.BadRequest(ctx)
+		return
+	}
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	if !auth.HasAccessPerm(user, body.UserID, false, true, false) {
+		response.Forbidden(ctx)
+		return
+	}
+	asset := models.Asset{
+		UserID: body.UserID,
+		Name:   body.Name,
+		Type:   body.Type,
+		Notes:  body.Notes,
+	}
+	if db.Create(&asset).Error != nil {
+		response.BadRequest(ctx)
+		return
+	}
+	response.Created(ctx, asset)
+}
+
+// Fetch the asset given by the id parameter, checking the user has the given access to it.
+// An error response has been sent if ok is false.
+func assetFromContext(ctx *gin.Context, write bool) (models.Asset, bool) {
+	errs := []error{}
+	id := util.ParseUint(ctx.Param("id"), &errs)
+	if len(errs) > 0 {
+		response.BadRequest(ctx)
+		return models.Asset{}, false
+	}
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	asset, err := database.GetAsset(db, id)
+	if err != nil {
+		response.NotFound(ctx)
+		return asset, false
+	}
+	if !auth.HasAccessPerm(user, asset.UserID, !write, write, false) {
+		response.Forbidden(ctx)
+		return asset, false
+	}
+	return asset, true
+}
+
+func UpdateAsset(ctx *gin.Context) {
+	var body models.AssetRequest
+	if ctx.BindJSON(&body) != nil || !slices.Contains(constants.ASSET_TYPES, body.Type) {
+		response.BadRequest(ctx)
+		return
+	}
+	asset, ok := assetFromContext(ctx, true)
+	if !ok {
+		return
+	}
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	if !auth.HasAccessPerm(user, body.UserID, false, true, false) {
+		response.Forbidden(ctx)
+		return
+	}
+	asset.UserID = body.UserID
+	asset.Name = body.Name
+	asset.Notes = body.Notes
+	signChanged := asset.IsLiability() != slices.Contains(constants.LIABILITY_ASSET_TYPES, body.Type)
+	asset.Type = body.Type
+	db.Omit("Valuations").Save(&asset)
+	if signChanged && len(asset.Valuations) > 0 {
+		// Keep the sign of existing valuations consistent with the new type.
+		for i, valuation := range asset.Valuations {
+			asset.Valuations[i].Value = asset.SignedValue(valuation.Value)
+		}
+		db.Save(&asset.Valuations)
+	}
+	response.OK(ctx, asset)
+}
+
+func DeleteAsset(ctx *gin.Context) {
+	asset, ok := assetFromContext(ctx, true)
+	if !ok {
+		return
+	}
+	database.DeleteAsset(middleware.GetDB(ctx), asset)
+	response.NoContent(ctx)
+}
+
+func CreateValuation(ctx *gin.Context) {
+	var body models.AssetValuationRequest
+	if ctx.BindJSON(&body) != nil {
+		response.BadRequest(ctx)
+		return
+	}
+	asset, ok := assetFromContext(ctx, true)
+	if !ok {
+		return
+	}
+	valuation := models.AssetValuation{
+		AssetID: asset.ID,
+		Date:    time.Unix(body.Date, 0),
+		Value:   asset.SignedValue(body.Value),
+	}
+	if middleware.GetDB(ctx).Create(&valuation).Error != nil {
+		response.BadRequest(ctx)
+		return
+	}
+	response.Created(ctx, valuation)
+}
+
+func DeleteValuation(ctx *gin.Context) {
+	errs := []error{}
+	valuationID := util.ParseUint(ctx.Param("valuation"), &errs)
+	if len(errs) > 0 {
+		response.BadRequest(ctx)
+		return
+	}
+	asset, ok := assetFromContext(ctx, true)
+	if !ok {
+		return
+	}
+	db := middleware.GetDB(ctx)
+	valuation, err := database.GetValuation(db, asset.ID, valuationID)
+	if err != nil {
+		response.NotFound(ctx)
+		return
+	}
+	db.Delete(&valuation)
+	response.NoContent(ctx)
+}
+
+func RegisterAssetRoutes(router *gin.RouterGroup) {
+	router.GET("/assets", middleware.Authenticate("AccessPermissions"), GetAssets)
+	router.POST("/assets", middleware.Authenticate("AccessPermissions"), CreateAsset)
+	router.PUT("/assets/:id", middleware.Authenticate("AccessPermissions"), UpdateAsset)
+	router.DELETE("/assets/:id", middleware.Authenticate("AccessPermissions"), DeleteAsset)
+	router.POST("/assets/:id/valuations", middleware.Authenticate("AccessPermissions"), CreateValuation)
+	router.DELETE("/assets/:id/valuations/:valuation", middleware.Authenticate("AccessPermissions"), DeleteValuation)
+}
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/goldsproutapp/goldsprout-backend/config"
 	"github.com/goldsproutapp/goldsprout-backend/constants"
 	"github.com/goldsproutapp/goldsprout-backend/database"
+	"github.com/goldsproutapp/goldsprout-backend/email"
 	"github.com/goldsproutapp/goldsprout-backend/middleware"
 	"github.com/goldsproutapp/goldsprout-backend/models"
 	"github.com/goldsproutapp/goldsprout-backend/request/response"
@@ -17,6 +19,15 @@ import (
 	"gorm.io/gorm"
 )
 
+// Local passwords can't be set or used when local login is disabled.
+func localLoginDisabled(ctx *gin.Context) bool {
+	if config.LocalLoginEnabled() {
+		return false
+	}
+	ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "local login is disabled"})
+	return true
+}
+
 func Login(ctx *gin.Context) {
 	header := ctx.GetHeader("Authorization")
 	parts := strings.Split(header, ":")
@@ -31,14 +42,46 @@ func Login(ctx *gin.Context) {
 		user = database.GetDemoUser(db)
 		token = constants.DEMO_USER_AUTH_TOKEN
 	} else {
+		if localLoginDisabled(ctx) {
+			return
+		}
 		var err error
 		user, err = auth.AuthenticateUnamePw(db, parts[0], parts[1])
 		if err != nil {
 			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "invalid username or password"})
 			return
 		}
-		token = auth.CreateToken(db, user, util.FormatUA(ctx.Request.UserAgent()))
+		startSession(ctx, db, user)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"token":   token,
+		"data":    user,
+	})
+}
+
+func twoFactorLocked(ctx *gin.Context) {
+	ctx.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"message": "too many failed attempts, try again later"})
+}
+
+// Issue a session token for an authenticated user, or a login challenge if they need a second factor.
+func startSession(ctx *gin.Context, db *gorm.DB, user models.User) {
+	if auth.RequiresTwoFactor(db, user) {
+		challenge, err := auth.CreateLoginChallenge(db, user, util.FormatUA(ctx.Request.UserAgent()))
+		if err != nil {
+			twoFactorLocked(ctx)
+			return
+		}
+		ctx.JSON(http.StatusOK, gin.H{
+			"success":             true,
+			"two_factor_required": true,
+			"enrolment_required":  !user.TOTPEnabled,
+			"challenge":           challenge,
+		})
+		return
 	}
+	token := auth.CreateToken(db, user, util.FormatUA(ctx.Request.UserAgent()))
 	ctx.JSON(http.StatusOK, gin.H{
 		"success": true,
 		"token":   token,
@@ -46,16 +89,201 @@ func Login(ctx *gin.Context) {
 	})
 }
 
+func GetLoginMethods(ctx *gin.Context) {
+	response.OK(ctx, gin.H{
+		"local_login_enabled": config.LocalLoginEnabled(),
+		"oidc_enabled":        config.OIDCEnabled(),
+	})
+}
+
+// The identity provider's URL, to which the frontend should redirect.
+func BeginOIDCLogin(ctx *gin.Context) {
+	if !config.OIDCEnabled() {
+		response.NotFound(ctx)
+		return
+	}
+	db := middleware.GetDB(ctx)
+	url, err := auth.BeginOIDCLogin(ctx.Request.Context(), db)
+	if err != nil {
+		response.InternalServerError(ctx)
+		return
+	}
+	response.OK(ctx, gin.H{"url": url})
+}
+
+// Called by the frontend with the code and state it was redirected back with.
+func CompleteOIDCLogin(ctx *gin.Context) {
+	if !config.OIDCEnabled() {
+		response.NotFound(ctx)
+		return
+	}
+	db := middleware.GetDB(ctx)
+	var body models.OIDCCallbackRequest
+	if ctx.BindJSON(&body) != nil {
+		response.BadRequest(ctx)
+		return
+	}
+	identity, err := auth.CompleteOIDCLogin(ctx.Request.Context(), db, body.Code, body.State)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": err.Error()})
+		return
+	}
+	user, err := auth.UserForOIDCIdentity(db, identity, config.OIDCJITProvisioningEnabled())
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": err.Error()})
+		return
+	}
+	startSession(ctx, db, user)
+}
+
+// Complete a login with a TOTP or recovery code. If the user is enrolling as part of the login,
+// the code must be for their new secret, and their recovery codes are returned.
+func TwoFactorLogin(ctx *gin.Context) {
+	db := middleware.GetDB(ctx)
+	var body models.TwoFactorLoginRequest
+	if ctx.BindJSON(&body) != nil {
+		response.BadRequest(ctx)
+		return
+	}
+	challenge, err := auth.UseLoginChallenge(db, body.Challenge)
+	if err == auth.ErrTwoFactorLocked {
+		twoFactorLocked(ctx)
+		return
+	} else if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "invalid or expired challenge"})
+		return
+	}
+	user := challenge.User
+	var recoveryCodes []string
+	if challenge.Enrol {
+		if !auth.VerifyTOTP(db, &user, body.Code) {
+			auth.RecordTwoFactorFailure(db, &user)
+			ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "invalid code"})
+			return
+		}
+		recoveryCodes = auth.EnableTwoFactor(db, &user)
+	} else if !auth.VerifySecondFactor(db, &user, body.Code) {
+		auth.RecordTwoFactorFailure(db, &user)
+		ctx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "invalid code"})
+		return
+	}
+	auth.ResetTwoFactorFailures(db, &user)
+	db.Delete(&challenge)
+	token := auth.CreateToken(db, user, challenge.Client)
+	out := gin.H{
+		"success": true,
+		"token":   token,
+		"data":    user,
+	}
+	if recoveryCodes != nil {
+		out["recovery_codes"] = recoveryCodes
+	}
+	ctx.JSON(http.StatusOK, out)
+}
+
+// Begin enrolment for a user who is required to use two-factor authentication but has not yet enrolled.
+func TwoFactorLoginEnrol(ctx *gin.Context) {
+	db := middleware.GetDB(ctx)
+	var body models.TwoFactorChallengeRequest
+	if ctx.BindJSON(&body) != nil {
+		response.BadRequest(ctx)
+		return
+	}
+	challenge, err := auth.UseLoginChallenge(db, body.Challenge)
+	if err == auth.ErrTwoFactorLocked {
+		twoFactorLocked(ctx)
+		return
+	} else if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "invalid or expired challenge"})
+		return
+	}
+	if !challenge.Enrol {
+		response.BadRequest(ctx)
+		return
+	}
+	user := challenge.User
+	secret := auth.BeginTOTPEnrolment(db, &user)
+	response.OK(ctx, gin.H{"secret": secret, "uri": auth.TOTPURI(secret, user.Email)})
+}
+
+func BeginTwoFactorEnrolment(ctx *gin.Context) {
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	if user.TOTPEnabled || user.IsDemoUser {
+		response.Conflict(ctx)
+		return
+	}
+	secret := auth.BeginTOTPEnrolment(db, &user)
+	response.OK(ctx, gin.H{"secret": secret, "uri": auth.TOTPURI(secret, user.Email)})
+}
+
+func ConfirmTwoFactorEnrolment(ctx *gin.Context) {
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	var body models.TwoFactorCodeRequest
+	if ctx.BindJSON(&body) != nil {
+		response.BadRequest(ctx)
+		return
+	}
+	if user.TOTPEnabled {
+		response.Conflict(ctx)
+		return
+	}
+	if !auth.VerifyTOTP(db, &user, body.Code) {
+		response.Forbidden(ctx)
+		return
+	}
+	response.OK(ctx, gin.H{"recovery_codes": auth.EnableTwoFactor(db, &user)})
+}
+
+func RegenerateRecoveryCodes(ctx *gin.Context) {
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	var body models.TwoFactorCodeRequest
+	if ctx.BindJSON(&body) != nil {
+		response.BadRequest(ctx)
+		return
+	}
+	if !user.TOTPEnabled || !auth.VerifyTOTP(db, &user, body.Code) {
+		response.Forbidden(ctx)
+		return
+	}
+	response.OK(ctx, gin.H{"recovery_codes": auth.RegenerateRecoveryCodes(db, user)})
+}
+
+func DisableTwoFactor(ctx *gin.Context) {
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	var body models.TwoFactorCodeRequest
+	if ctx.BindJSON(&body) != nil {
+		response.BadRequest(ctx)
+		return
+	}
+	if user.IsAdmin && auth.GetInstanceSettings(db).RequireAdminTwoFactor {
+		response.Forbidden(ctx)
+		return
+	}
+	if !user.TOTPEnabled || !auth.VerifySecondFactor(db, &user, body.Code) {
+		response.Forbidden(ctx)
+		return
+	}
+	auth.DisableTwoFactor(db, &user)
+	response.NoContent(ctx)
+}
+
 func Logout(ctx *gin.Context) {
 	db := middleware.GetDB(ctx)
 	session := middleware.GetSession(ctx)
-	if !session.IsDemoSession {
+	if !session.IsDemoSession && !session.IsTokenSession {
 		db.Delete(&session)
 	}
 	response.NoContent(ctx)
 }
 
 func AcceptInvitation(ctx *gin.Context) {
+	if localLoginDisabled(ctx) {
+		return
+	}
 	db := middleware.GetDB(ctx)
 	var body models.UserInvitationAccept
 	err := ctx.BindJSON(&body)
@@ -80,6 +308,9 @@ func AcceptInvitation(ctx *gin.Context) {
 }
 
 func ChangePassword(ctx *gin.Context) {
+	if localLoginDisabled(ctx) {
+		return
+	}
 	db := middleware.GetDB(ctx)
 	user := middleware.GetUser(ctx)
 	var body models.PasswordChangeRequest
@@ -93,12 +324,71 @@ func ChangePassword(ctx *gin.Context) {
 	}
 	user.PasswordHash = auth.HashAndSalt(body.NewPassword)
 	db.Save(&user)
+	database.RevokeOtherSessions(db, user.ID, middleware.GetSession(ctx).ID)
 	ctx.Status(http.StatusOK)
 }
 
+// The response is the same whether or not the email is registered. The lookup, token and email
+// are all handled in the background so that the response time doesn't reveal it either.
+func ForgotPassword(ctx *gin.Context) {
+	db := middleware.GetDB(ctx)
+	var body models.PasswordForgottenRequest
+	if ctx.BindJSON(&body) != nil {
+		response.BadRequest(ctx)
+		return
+	}
+	if config.LocalLoginEnabled() {
+		util.Background("password reset", func() {
+			var user models.User
+			res := db.Where(models.User{Email: body.Email, Active: true}).First(&user)
+			if res.Error != nil || user.PasswordHash == "" || user.IsDemoUser {
+				return
+			}
+			token := auth.CreatePasswordResetToken(db, user)
+			expiry := fmt.Sprintf("%d minutes", int(constants.PASSWORD_RESET_DURATION.Minutes()))
+			if !email.SendPasswordReset(user.Email, token, expiry) {
+				fmt.Println("Error sending password reset email")
+			}
+		})
+	}
+	response.OK(ctx, gin.H{"message": "if the email is registered, a reset link has been sent"})
+}
+
+func ResetPassword(ctx *gin.Context) {
+	if localLoginDisabled(ctx) {
+		return
+	}
+	db := middleware.GetDB(ctx)
+	var body models.PasswordResetRequest
+	if ctx.BindJSON(&body) != nil {
+		response.BadRequest(ctx)
+		return
+	}
+	user, err := auth.UsePasswordResetToken(db, body.Token)
+	if err != nil {
+		response.BadRequest(ctx)
+		return
+	}
+	user.PasswordHash = auth.HashAndSalt(body.Password)
+	db.Model(&user).Update("password_hash", user.PasswordHash)
+	database.RevokeOtherSessions(db, user.ID, 0)
+	response.NoContent(ctx)
+}
+
 func RegisterAuthRoutes(router *gin.RouterGroup) {
 	router.POST("/login", Login)
+	router.GET("/login/methods", GetLoginMethods)
+	router.GET("/login/oidc", BeginOIDCLogin)
+	router.POST("/login/oidc/callback", CompleteOIDCLogin)
+	router.POST("/login/2fa", TwoFactorLogin)
+	router.POST("/login/2fa/enrol", TwoFactorLoginEnrol)
 	router.POST("/logout", middleware.Authenticate(), Logout)
 	router.POST("/acceptInvitation", AcceptInvitation)
 	router.PATCH("/changepassword", middleware.Authenticate(), ChangePassword)
+	router.POST("/password/forgot", ForgotPassword)
+	router.POST("/password/reset", ResetPassword)
+	router.POST("/2fa/enrol", middleware.Authenticate(), BeginTwoFactorEnrolment)
+	router.POST("/2fa/verify", middleware.Authenticate(), ConfirmTwoFactorEnrolment)
+	router.POST("/2fa/recovery", middleware.Authenticate(), RegenerateRecoveryCodes)
+	router.DELETE("/2fa", middleware.Authenticate(), DisableTwoFactor)
 }
@@ -1,73 +1,198 @@
 package routes
 
 import (
+	"encoding/csv"
+	"io"
+	"slices"
 	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/goldsproutapp/goldsprout-backend/calculations/reports"
 	"github.com/goldsproutapp/goldsprout-backend/constants"
 	"github.com/goldsproutapp/goldsprout-backend/database"
+	"github.com/goldsproutapp/goldsprout-backend/lib/export"
+	"github.com/goldsproutapp/goldsprout-backend/lib/extraction/times"
 	"github.com/goldsproutapp/goldsprout-backend/middleware"
 	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/goldsproutapp/goldsprout-backend/request"
 	"github.com/goldsproutapp/goldsprout-backend/request/response"
-	"gorm.io/gorm/clause"
+	"github.com/goldsproutapp/goldsprout-backend/util"
 )
 
-var headings = []string{
-	"date",
-	"user",
-	"provider",
-	"account",
-	"stock_code",
-	"stock_name",
-	"region",
-	"sector",
-	"annual_fee",
-	"units",
-	"price",
-	"cost",
-	"value",
-	"absolute_change",
-	"normalised_performance",
-	"transaction_attribution",
+type csvColumn struct {
+	name   string
+	format func(models.StockSnapshot) string
 }
 
-func FormatCSV(snapshot models.StockSnapshot) string {
-	fields := []string{
-		snapshot.Date.Format(constants.ISO8601),
-		snapshot.User.Name(),
-		snapshot.Stock.Provider.Name,
-		snapshot.Account.Name,
-		snapshot.Stock.StockCode,
-		snapshot.Stock.Name,
-		snapshot.Stock.Region,
-		snapshot.Stock.Sector,
-		strconv.FormatFloat(float64(snapshot.Stock.AnnualFee), 'f', 2, 64),
-		snapshot.Units.String(),
-		snapshot.Price.String(),
-		snapshot.Cost.String(),
-		snapshot.Value.String(),
-		snapshot.ChangeToDate.String(),
-		snapshot.NormalisedPerformance.String(),
-		strconv.FormatUint(uint64(snapshot.TransactionAttribution), 10),
-	}
-	return strings.Join(fields, ",")
+var csvColumns = []csvColumn{
+	{"date", func(s models.StockSnapshot) string { return s.Date.Format(constants.ISO8601) }},
+	{"user", func(s models.StockSnapshot) string { return s.User.Name() }},
+	{"provider", func(s models.StockSnapshot) string { return s.Stock.Provider.Name }},
+	{"account", func(s models.StockSnapshot) string { return s.Account.Name }},
+	{"stock_code", func(s models.StockSnapshot) string { return s.Stock.StockCode }},
+	{"stock_name", func(s models.StockSnapshot) string { return s.Stock.Name }},
+	{"region", func(s models.StockSnapshot) string { return s.Stock.Region }},
+	{"sector", func(s models.StockSnapshot) string { return s.Stock.Sector }},
+	{"annual_fee", func(s models.StockSnapshot) string {
+		return strconv.FormatFloat(float64(s.Stock.AnnualFee), 'f', 2, 64)
+	}},
+	{"units", func(s models.StockSnapshot) string { return s.Units.String() }},
+	{"price", func(s models.StockSnapshot) string { return s.Price.String() }},
+	{"cost", func(s models.StockSnapshot) string { return s.Cost.String() }},
+	{"value", func(s models.StockSnapshot) string { return s.Value.String() }},
+	{"absolute_change", func(s models.StockSnapshot) string { return s.ChangeToDate.String() }},
+	{"normalised_performance", func(s models.StockSnapshot) string { return s.NormalisedPerformance.String() }},
+	{"transaction_attribution", func(s models.StockSnapshot) string {
+		return strconv.FormatUint(uint64(s.TransactionAttribution), 10)
+	}},
+	{"tags", func(s models.StockSnapshot) string { return strings.Join(s.Tags, ";") }},
+}
+
+// The requested columns in the order given, or every column if none are requested.
+func selectCSVColumns(names []string) ([]csvColumn, bool) {
+	if len(names) == 0 {
+		return csvColumns, true
+	}
+	out := make([]csvColumn, len(names))
+	for i, name := range names {
+		idx := slices.IndexFunc(csvColumns, func(c csvColumn) bool { return c.name == name })
+		if idx == -1 {
+			return nil, false
+		}
+		out[i] = csvColumns[idx]
+	}
+	return out, true
+}
+
+func FormatCSV(snapshot models.StockSnapshot, columns []csvColumn) []string {
+	fields := make([]string, len(columns))
+	for i, column := range columns {
+		fields[i] = column.format(snapshot)
+	}
+	return fields
 }
 
 func ExportToCSV(ctx *gin.Context) {
+	var query models.ExportCSVRequestQuery
+	if ctx.BindQuery(&query) != nil {
+		response.BadRequest(ctx)
+		return
+	}
+	columns, ok := selectCSVColumns(util.Split(query.Columns, ","))
+	if !ok {
+		response.BadRequest(ctx)
+		return
+	}
+	user := middleware.GetUser(ctx)
+	db := middleware.GetDB(ctx)
+	filter := request.BuildStockFilter(query.StockFilterQuery)
+
+	response.StreamFileOK(ctx, "export.csv", "text/csv", func(w io.Writer) error {
+		writer := csv.NewWriter(w)
+		header := make([]string, len(columns))
+		for i, column := range columns {
+			header[i] = column.name
+		}
+		if err := writer.Write(header); err != nil {
+			return err
+		}
+		return database.StreamFilteredSnapshots(db, user, filter, false, query.Latest, constants.EXPORT_BATCH_SIZE,
+			func(batch []models.StockSnapshot) error {
+				for _, snapshot := range batch {
+					if err := writer.Write(FormatCSV(snapshot, columns)); err != nil {
+						return err
+					}
+				}
+				writer.Flush()
+				return writer.Error()
+			})
+	})
+}
+
+func ExportToXLSX(ctx *gin.Context) {
+	var query models.ExportRequestQuery
+	if ctx.BindQuery(&query) != nil {
+		response.BadRequest(ctx)
+		return
+	}
+	reportQuery := models.ReportRequestQuery{StockFilterQuery: query.StockFilterQuery, Period: query.Period}
+	if reportQuery.Period == "" {
+		reportQuery.Period = "annual"
+	}
+	if !reports.IsReportQueryValid(reportQuery) {
+		response.BadRequest(ctx)
+		return
+	}
+	user := middleware.GetUser(ctx)
+	db := middleware.GetDB(ctx)
+	filter := request.BuildStockFilter(query.StockFilterQuery)
+	snapshots := database.GetFilteredSnapshots(db, user, filter, false)
+
+	providers := map[uint]string{}
+	for _, provider := range database.GetProviders(db) {
+		providers[provider.ID] = provider.Name
+	}
+	periods, reportMap := reports.CalculateReport(db, filter, reportQuery, times.UserYearStart(user), snapshots)
+
+	workbook := export.NewWorkbook()
+	sheets := []struct {
+		name     string
+		headings []string
+		rows     [][]any
+	}{
+		{"Snapshots", export.SnapshotHeadings, export.SnapshotRows(snapshots)},
+		{"Holdings", export.HoldingHeadings, export.HoldingRows(snapshots)},
+		{"Accounts", export.AccountHeadings, export.AccountRows(snapshots, providers)},
+		{"Stocks", export.StockHeadings, export.StockRows(snapshots)},
+		{"Report", export.ReportHeadings, export.ReportRows(periods, reportMap)},
+		{"Split", export.SplitHeadings, export.SplitRows(snapshots, []string{"region", "sector", "provider", "stock", "account", "class"})},
+	}
+	for _, sheet := range sheets {
+		if err := workbook.AddSheet(sheet.name, sheet.headings, sheet.rows); err != nil {
+			response.InternalServerError(ctx)
+			return
+		}
+	}
+	content, err := workbook.Bytes()
+	if err != nil {
+		response.InternalServerError(ctx)
+		return
+	}
+	response.BinaryFileOK(ctx, "export.xlsx", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", content)
+}
+
+func ExportToJournal(ctx *gin.Context) {
+	var query models.ExportJournalRequestQuery
+	if ctx.BindQuery(&query) != nil || !slices.Contains(constants.JOURNAL_FORMATS, query.Format) {
+		response.BadRequest(ctx)
+		return
+	}
+	currency := strings.ToUpper(query.Currency)
+	if currency == "" {
+		currency = constants.DEFAULT_JOURNAL_CURRENCY
+	}
+	if !export.IsValidCommodity(currency) {
+		response.BadRequest(ctx)
+		return
+	}
 	user := middleware.GetUser(ctx)
 	db := middleware.GetDB(ctx)
-	snapshots := database.GetAllVisibleSnapshots(user, db, false, clause.Associations, "Stock.Provider")
-	outputArr := make([]string, len(snapshots)+1)
-	outputArr[0] = strings.Join(headings, ",")
-	for i, snapshot := range snapshots {
-		str := FormatCSV(snapshot)
-		outputArr[i+1] = str
-	}
-	output := strings.Join(outputArr, "\n")
-	response.FileOK(ctx, "export.csv", output)
+	filter := request.BuildStockFilter(query.StockFilterQuery)
+	snapshots := database.GetFilteredSnapshots(db, user, filter, false)
+	transactions := reports.InferTransactions(db, snapshots)
+
+	filename := "export.beancount"
+	if query.Format == constants.JOURNAL_FORMAT_HLEDGER {
+		filename = "export.journal"
+	}
+	response.StreamFileOK(ctx, filename, "text/plain", func(w io.Writer) error {
+		return export.WriteJournal(w, query.Format, currency, snapshots, transactions)
+	})
 }
 
 func RegisterExportRoutes(router *gin.RouterGroup) {
 	router.GET("/export/csv", middleware.Authenticate("AccessPermissions"), ExportToCSV)
+	router.GET("/export/xlsx", middleware.Authenticate("AccessPermissions"), ExportToXLSX)
+	router.GET("/export/journal", middleware.Authenticate("AccessPermissions"), ExportToJournal)
 }
@@ -0,0 +1,164 @@
+package routes
+
+import (
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goldsproutapp/goldsprout-backend/auth"
+	"github.com/goldsproutapp/goldsprout-backend/calculations/goals"
+	"github.com/goldsproutapp/goldsprout-backend/database"
+	"github.com/goldsproutapp/goldsprout-backend/middleware"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/goldsproutapp/goldsprout-backend/request/response"
+	"github.com/goldsproutapp/goldsprout-backend/util"
+	"gorm.io/gorm"
+)
+
+// Progress for each goal, loading the history of all their users at once.
+func goalsProgress(db *gorm.DB, user models.User, visible []models.Goal) []goals.GoalProgress {
+	goalUsers := make([][]uint, len(visible))
+	users := []uint{}
+	holdings := []uint{}
+	for i, goal := range visible {
+		goalUsers[i] = database.GetGoalUsers(db, goal)
+		users = append(users, goalUsers[i]...)
+		holdings = append(holdings, goal.Holdings...)
+	}
+	out := make([]goals.GoalProgress, len(visible))
+	if len(visible) == 0 {
+		return out
+	}
+	snapshots := database.GetFilteredSnapshots(db, user, database.StockFilter{Users: users}, false)
+	userStocks := database.GetUserStocksByID(db, holdings)
+	now := time.Now()
+	for i, goal := range visible {
+		owned := []models.StockSnapshot{}
+		for _, snapshot := range snapshots {
+			if slices.Contains(goalUsers[i], snapshot.UserID) {
+				owned = append(owned, snapshot)
+			}
+		}
+		out[i] = goals.CalculateGoalProgress(goal, goals.GoalSnapshots(goal, userStocks, owned), now)
+	}
+	return out
+}
+
+func applyGoalRequest(goal *models.Goal, body models.GoalRequest) {
+	goal.UserID = body.UserID
+	goal.Name = body.Name
+	goal.TargetAmount = body.TargetAmount
+	goal.TargetDate = time.Unix(body.TargetDate, 0)
+	goal.Household = body.Household
+	goal.Accounts = body.Accounts
+	goal.Holdings = body.Holdings
+	goal.Tags = database.NormaliseTags(body.Tags)
+}
+
+func GetGoals(ctx *gin.Context) {
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	visible, err := database.GetVisibleGoals(db, user)
+	if err != nil {
+		response.BadRequest(ctx)
+		return
+	}
+	response.OK(ctx, goalsProgress(db, user, visible))
+}
+
+func GetGoal(ctx *gin.Context) {
+	errs := []error{}
+	id := util.ParseUint(ctx.Param("id"), &errs)
+	if len(errs) > 0 {
+		response.BadRequest(ctx)
+		return
+	}
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	goal, err := database.GetGoal(db, id)
+	if err != nil {
+		response.NotFound(ctx)
+		return
+	}
+	if !auth.HasAccessPerm(user, goal.UserID, true, false, false) {
+		response.Forbidden(ctx)
+		return
+	}
+	response.OK(ctx, goalsProgress(db, user, []models.Goal{goal})[0])
+}
+
+func CreateGoal(ctx *gin.Context) {
+	var body models.GoalRequest
+	if ctx.BindJSON(&body) != nil || !goals.IsGoalValid(body) {
+		response.BadRequest(ctx)
+		return
+	}
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	if !auth.HasAccessPerm(user, body.UserID, false, true, false) {
+		response.Forbidden(ctx)
+		return
+	}
+	goal := models.Goal{}
+	applyGoalRequest(&goal, body)
+	if db.Create(&goal).Error != nil {
+		response.BadRequest(ctx)
+		return
+	}
+	response.Created(ctx, goal)
+}
+
+func UpdateGoal(ctx *gin.Context) {
+	errs := []error{}
+	id := util.ParseUint(ctx.Param("id"), &errs)
+	var body models.GoalRequest
+	if len(errs) > 0 || ctx.BindJSON(&body) != nil || !goals.IsGoalValid(body) {
+		response.BadRequest(ctx)
+		return
+	}
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	goal, err := database.GetGoal(db, id)
+	if err != nil {
+		response.NotFound(ctx)
+		return
+	}
+	if !auth.HasAccessPerm(user, goal.UserID, false, true, false) ||
+		!auth.HasAccessPerm(user, body.UserID, false, true, false) {
+		response.Forbidden(ctx)
+		return
+	}
+	applyGoalRequest(&goal, body)
+	db.Save(&goal)
+	response.OK(ctx, goal)
+}
+
+func DeleteGoal(ctx *gin.Context) {
+	errs := []error{}
+	id := util.ParseUint(ctx.Param("id"), &errs)
+	if len(errs) > 0 {
+		response.BadRequest(ctx)
+		return
+	}
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	goal, err := database.GetGoal(db, id)
+	if err != nil {
+		response.NotFound(ctx)
+		return
+	}
+	if !auth.HasAccessPerm(user, goal.UserID, false, true, false) {
+		response.Forbidden(ctx)
+		return
+	}
+	database.DeleteGoal(db, goal)
+	response.NoContent(ctx)
+}
+
+func RegisterGoalRoutes(router *gin.RouterGroup) {
+	router.GET("/goals", middleware.Authenticate("AccessPermissions"), GetGoals)
+	router.GET("/goals/:id", middleware.Authenticate("AccessPermissions"), GetGoal)
+	router.POST("/goals", middleware.Authenticate("AccessPermissions"), CreateGoal)
+	router.PUT("/goals/:id", middleware.Authenticate("AccessPermissions"), UpdateGoal)
+	router.DELETE("/goals/:id", middleware.Authenticate("AccessPermissions"), DeleteGoal)
+}
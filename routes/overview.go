@@ -2,8 +2,10 @@ package routes
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/goldsproutapp/goldsprout-backend/calculations/networth"
 	"github.com/goldsproutapp/goldsprout-backend/database"
 	"github.com/goldsproutapp/goldsprout-backend/middleware"
 )
@@ -12,6 +14,13 @@ func Overview(ctx *gin.Context) {
 	db := middleware.GetDB(ctx)
 	user := middleware.GetUser(ctx)
 	overview := database.GetOverview(db, user)
+	// The history needs every snapshot, so is only built when asked for.
+	if ctx.Query("net_worth_history") == "true" {
+		uids := database.GetVisibleUserIDs(db, user)
+		assets, _ := database.GetAssets(db, uids)
+		snapshots := database.GetSnapshots(uids, []uint{}, db)
+		overview.NetWorthHistory = networth.CalculateNetWorthHistory(snapshots, assets, time.Now())
+	}
 	ctx.JSON(http.StatusOK, overview)
 
 }
@@ -6,9 +6,13 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/goldsproutapp/goldsprout-backend/auth"
 	"github.com/goldsproutapp/goldsprout-backend/calculations/performance"
+	"github.com/goldsproutapp/goldsprout-backend/calculations/rolling"
 	"github.com/goldsproutapp/goldsprout-backend/database"
+	"github.com/goldsproutapp/goldsprout-backend/lib/extraction/times"
+	"github.com/goldsproutapp/goldsprout-backend/lib/processing"
 	"github.com/goldsproutapp/goldsprout-backend/middleware"
 	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/goldsproutapp/goldsprout-backend/request"
 	"github.com/goldsproutapp/goldsprout-backend/request/response"
 	"github.com/goldsproutapp/goldsprout-backend/util"
 	"github.com/shopspring/decimal"
@@ -48,10 +52,36 @@ func StockPerformance(ctx *gin.Context) {
 }
 
 func PortfolioPerformance(ctx *gin.Context) {
+	var query models.PortfolioPerformanceRequestQuery
+	if ctx.BindQuery(&query) != nil || (query.Resolution != "" && !processing.IsValidResolution(query.Resolution)) {
+		response.BadRequest(ctx)
+		return
+	}
 	user := middleware.GetUser(ctx)
 	db := middleware.GetDB(ctx)
-	snapshots := database.GetSnapshots([]uint{user.ID}, []uint{}, db)
-	info := performance.GeneratePerformanceGraphInfo(snapshots)
+	filter := request.BuildStockFilter(query.StockFilterQuery)
+	if len(filter.Users) == 0 {
+		// Only the user's own portfolio unless others are explicitly requested.
+		filter.Users = []uint{user.ID}
+	}
+	for _, uid := range filter.Users {
+		if !auth.HasAccessPerm(user, uid, true, false, false) {
+			response.Forbidden(ctx)
+			return
+		}
+	}
+	snapshots := database.GetFilteredSnapshots(db, user, filter, false)
+	if query.Resolution == "" {
+		info := performance.GeneratePerformanceGraphInfo(snapshots, times.UserYearStart(user))
+		response.OK(ctx, info)
+		return
+	}
+	stockIDs := util.NewHashSet[uint]()
+	for _, snapshot := range snapshots {
+		stockIDs.Add(snapshot.StockID)
+	}
+	prices := database.GetPriceHistory(db, stockIDs.Items())
+	info := performance.GenerateRegularPerformanceGraphInfo(snapshots, prices, times.UserYearStart(user), query.Resolution)
 	response.OK(ctx, info)
 }
 
@@ -78,12 +108,33 @@ func AccountPerformance(ctx *gin.Context) {
 		return
 	}
 	snapshots := database.GetAccountSnapshots(uint(id), db)
-	info := performance.GeneratePerformanceGraphInfo(snapshots)
+	info := performance.GeneratePerformanceGraphInfo(snapshots, times.UserYearStart(user))
 	response.OK(ctx, info)
 }
 
+func RollingReturns(ctx *gin.Context) {
+	var query models.RollingReturnsRequestQuery
+	if ctx.BindQuery(&query) != nil {
+		response.BadRequest(ctx)
+		return
+	}
+	if query.By == "" {
+		query.By = "all"
+	}
+	if !rolling.IsRollingQueryValid(query) {
+		response.BadRequest(ctx)
+		return
+	}
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	filter := request.BuildStockFilter(query.StockFilterQuery)
+	snapshots := database.GetFilteredSnapshots(db, user, filter, false)
+	response.OK(ctx, rolling.CalculateRollingReturns(snapshots, query.By))
+}
+
 func RegisterPerformanceRoutes(router *gin.RouterGroup) {
 	router.GET("/stockperformance", middleware.Authenticate("AccessPermissions"), StockPerformance)
-	router.GET("/portfolioperformance", middleware.Authenticate(), PortfolioPerformance)
+	router.GET("/portfolioperformance", middleware.Authenticate("AccessPermissions"), PortfolioPerformance)
 	router.GET("/accountperformance", middleware.Authenticate("AccessPermissions"), AccountPerformance)
+	router.GET("/returns/rolling", middleware.Authenticate("AccessPermissions"), RollingReturns)
 }
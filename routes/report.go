@@ -1,9 +1,13 @@
 package routes
 
 import (
+	"time"
+
 	"github.com/gin-gonic/gin"
+	"github.com/goldsproutapp/goldsprout-backend/calculations/capitalgains"
 	"github.com/goldsproutapp/goldsprout-backend/calculations/reports"
 	"github.com/goldsproutapp/goldsprout-backend/database"
+	"github.com/goldsproutapp/goldsprout-backend/lib/extraction/times"
 	"github.com/goldsproutapp/goldsprout-backend/middleware"
 	"github.com/goldsproutapp/goldsprout-backend/models"
 	"github.com/goldsproutapp/goldsprout-backend/request"
@@ -21,11 +25,52 @@ func Report(ctx *gin.Context) {
 	db := middleware.GetDB(ctx)
 	user := middleware.GetUser(ctx)
 	snapshots := database.GetFilteredSnapshots(db, user, filter, false)
-	times, reportMap := reports.CalculateReport(db, filter, query, snapshots)
+	times, reportMap := reports.CalculateReport(db, filter, query, times.UserYearStart(user), snapshots)
 
 	response.OK(ctx, gin.H{"periods": times, "report": reportMap})
 }
 
+func CompareReports(ctx *gin.Context) {
+	var query models.ReportComparisonRequestQuery
+	err := ctx.BindQuery(&query)
+	if err != nil || !reports.IsReportComparisonQueryValid(query) {
+		response.BadRequest(ctx)
+		return
+	}
+	filter := request.BuildStockFilter(query.StockFilterQuery)
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	snapshots := database.GetFilteredSnapshots(db, user, filter, false)
+	comparison, ok := reports.CompareReports(db, filter, query, times.UserYearStart(user), snapshots)
+	if !ok {
+		response.NotFound(ctx)
+		return
+	}
+	response.OK(ctx, comparison)
+}
+
+func CapitalGains(ctx *gin.Context) {
+	var query models.CapitalGainsRequestQuery
+	err := ctx.BindQuery(&query)
+	if err != nil || !capitalgains.IsCapitalGainsQueryValid(query) {
+		response.BadRequest(ctx)
+		return
+	}
+	filter := request.BuildStockFilter(query.StockFilterQuery)
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	// Earlier acquisitions are needed for the cost basis, so only the report is date-filtered.
+	historyFilter := filter
+	historyFilter.LowerDate = time.Unix(0, 0)
+	historyFilter.UpperDate = time.Unix(0, 0)
+	snapshots := database.GetFilteredSnapshots(db, user, historyFilter, false)
+	report := capitalgains.CalculateCapitalGains(filter, query.Method, snapshots)
+
+	response.OK(ctx, report)
+}
+
 func RegisterReportRoutes(router *gin.RouterGroup) {
 	router.GET("/report", middleware.Authenticate("AccessPermissions"), Report)
+	router.GET("/report/compare", middleware.Authenticate("AccessPermissions"), CompareReports)
+	router.GET("/report/capital-gains", middleware.Authenticate("AccessPermissions"), CapitalGains)
 }
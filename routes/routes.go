@@ -9,6 +9,8 @@ import (
 func RegisterAllRoutes(router *gin.RouterGroup, db *gorm.DB) {
 	router.Use(middleware.Database(db))
 	RegisterAuthRoutes(router)
+	RegisterSessionRoutes(router)
+	RegisterTokenRoutes(router)
 
 	RegisterStockRoutes(router)
 	RegisterSnapshotRoutes(router)
@@ -18,11 +20,17 @@ func RegisterAllRoutes(router *gin.RouterGroup, db *gorm.DB) {
 	RegisterOverviewRoutes(router)
 	RegisterSplitRoutes(router)
 	RegisterAccountRoutes(router)
+	RegisterAssetRoutes(router)
 	RegisterReportRoutes(router)
+	RegisterTargetRoutes(router)
+	RegisterGoalRoutes(router)
+	RegisterScenarioRoutes(router)
+	RegisterSimulationRoutes(router)
 
 	RegisterUserRoutes(router)
 	RegisterMiscRoutes(router)
 	RegisterAdminRoutes(router)
 	RegisterExportRoutes(router)
+	RegisterStatementRoutes(router)
 	RegisterPreferencesRoutes(router)
 }
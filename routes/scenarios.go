@@ -0,0 +1,144 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/goldsproutapp/goldsprout-backend/auth"
+	"github.com/goldsproutapp/goldsprout-backend/calculations/scenarios"
+	"github.com/goldsproutapp/goldsprout-backend/database"
+	"github.com/goldsproutapp/goldsprout-backend/middleware"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/goldsproutapp/goldsprout-backend/request"
+	"github.com/goldsproutapp/goldsprout-backend/request/response"
+	"github.com/goldsproutapp/goldsprout-backend/util"
+)
+
+func GetScenarios(ctx *gin.Context) {
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	saved, err := database.GetVisibleScenarios(db, user)
+	if err != nil {
+		response.BadRequest(ctx)
+		return
+	}
+	response.OK(ctx, saved)
+}
+
+func CreateScenario(ctx *gin.Context) {
+	var body models.ScenarioRequest
+	if ctx.BindJSON(&body) != nil || !scenarios.AreShocksValid(body.Shocks) {
+		response.BadRequest(ctx)
+		return
+	}
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	if !auth.HasAccessPerm(user, body.UserID, false, true, false) {
+		response.Forbidden(ctx)
+		return
+	}
+	scenario := models.Scenario{
+		UserID: body.UserID,
+		Name:   body.Name,
+	}
+	if db.Create(&scenario).Error != nil {
+		response.BadRequest(ctx)
+		return
+	}
+	database.ReplaceScenarioShocks(db, &scenario, body.Shocks)
+	response.Created(ctx, scenario)
+}
+
+func UpdateScenario(ctx *gin.Context) {
+	errs := []error{}
+	id := util.ParseUint(ctx.Param("id"), &errs)
+	var body models.ScenarioRequest
+	if len(errs) > 0 || ctx.BindJSON(&body) != nil || !scenarios.AreShocksValid(body.Shocks) {
+		response.BadRequest(ctx)
+		return
+	}
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	scenario, err := database.GetScenario(db, id)
+	if err != nil {
+		response.NotFound(ctx)
+		return
+	}
+	if !auth.HasAccessPerm(user, scenario.UserID, false, true, false) ||
+		!auth.HasAccessPerm(user, body.UserID, false, true, false) {
+		response.Forbidden(ctx)
+		return
+	}
+	scenario.UserID = body.UserID
+	scenario.Name = body.Name
+	db.Omit("Shocks").Save(&scenario)
+	database.ReplaceScenarioShocks(db, &scenario, body.Shocks)
+	response.OK(ctx, scenario)
+}
+
+func DeleteScenario(ctx *gin.Context) {
+	errs := []error{}
+	id := util.ParseUint(ctx.Param("id"), &errs)
+	if len(errs) > 0 {
+		response.BadRequest(ctx)
+		return
+	}
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	scenario, err := database.GetScenario(db, id)
+	if err != nil {
+		response.NotFound(ctx)
+		return
+	}
+	if !auth.HasAccessPerm(user, scenario.UserID, false, true, false) {
+		response.Forbidden(ctx)
+		return
+	}
+	database.DeleteScenario(db, scenario)
+	response.NoContent(ctx)
+}
+
+// Run ad-hoc shocks against the holdings selected by the stock filter.
+func RunScenario(ctx *gin.Context) {
+	var query models.StockFilterQuery
+	var body models.RunScenarioRequest
+	if ctx.BindQuery(&query) != nil || ctx.BindJSON(&body) != nil || !scenarios.AreShocksValid(body.Shocks) {
+		response.BadRequest(ctx)
+		return
+	}
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	snapshots := database.GetFilteredSnapshots(db, user, request.BuildStockFilter(query), false)
+	response.OK(ctx, scenarios.RunScenario(body.Shocks, snapshots))
+}
+
+// Re-run a saved scenario against the current holdings selected by the stock filter.
+func RunSavedScenario(ctx *gin.Context) {
+	errs := []error{}
+	id := util.ParseUint(ctx.Param("id"), &errs)
+	var query models.StockFilterQuery
+	if len(errs) > 0 || ctx.BindQuery(&query) != nil {
+		response.BadRequest(ctx)
+		return
+	}
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	scenario, err := database.GetScenario(db, id)
+	if err != nil {
+		response.NotFound(ctx)
+		return
+	}
+	if !auth.HasAccessPerm(user, scenario.UserID, true, false, false) {
+		response.Forbidden(ctx)
+		return
+	}
+	snapshots := database.GetFilteredSnapshots(db, user, request.BuildStockFilter(query), false)
+	response.OK(ctx, gin.H{"scenario": scenario, "result": scenarios.RunScenario(scenario.Shocks, snapshots)})
+}
+
+func RegisterScenarioRoutes(router *gin.RouterGroup) {
+	router.GET("/scenarios", middleware.Authenticate("AccessPermissions"), GetScenarios)
+	router.POST("/scenarios", middleware.Authenticate("AccessPermissions"), CreateScenario)
+	router.PUT("/scenarios/:id", middleware.Authenticate("AccessPermissions"), UpdateScenario)
+	router.DELETE("/scenarios/:id", middleware.Authenticate("AccessPermissions"), DeleteScenario)
+	router.POST("/scenarios/run", middleware.Authenticate("AccessPermissions"), RunScenario)
+	router.GET("/scenarios/:id/run", middleware.Authenticate("AccessPermissions"), RunSavedScenario)
+}
@@ -0,0 +1,69 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/goldsproutapp/goldsprout-backend/database"
+	"github.com/goldsproutapp/goldsprout-backend/middleware"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/goldsproutapp/goldsprout-backend/request/response"
+	"github.com/goldsproutapp/goldsprout-backend/util"
+)
+
+func GetSessions(ctx *gin.Context) {
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	current := middleware.GetSession(ctx)
+	sessions, err := database.GetUserSessions(db, user.ID)
+	if err != nil {
+		response.BadRequest(ctx)
+		return
+	}
+	out := make([]models.SessionResponse, len(sessions))
+	for i, session := range sessions {
+		out[i] = models.SessionResponse{
+			ID:         session.ID,
+			CreatedAt:  session.CreatedAt,
+			LastUsedAt: session.LastUsedAt,
+			Client:     session.Client,
+			Current:    session.ID == current.ID,
+		}
+	}
+	response.OK(ctx, out)
+}
+
+func RevokeSession(ctx *gin.Context) {
+	errs := []error{}
+	id := util.ParseUint(ctx.Param("id"), &errs)
+	if len(errs) > 0 {
+		response.BadRequest(ctx)
+		return
+	}
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	session, err := database.GetUserSession(db, user.ID, id)
+	if err != nil {
+		response.NotFound(ctx)
+		return
+	}
+	db.Delete(&session)
+	response.NoContent(ctx)
+}
+
+// Revoke every session except the one making the request.
+func RevokeOtherSessions(ctx *gin.Context) {
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	session := middleware.GetSession(ctx)
+	if session.IsDemoSession || session.IsTokenSession {
+		response.Forbidden(ctx)
+		return
+	}
+	database.RevokeOtherSessions(db, user.ID, session.ID)
+	response.NoContent(ctx)
+}
+
+func RegisterSessionRoutes(router *gin.RouterGroup) {
+	router.GET("/sessions", middleware.Authenticate(), GetSessions)
+	router.DELETE("/sessions", middleware.Authenticate(), RevokeOtherSessions)
+	router.DELETE("/sessions/:id", middleware.Authenticate(), RevokeSession)
+}
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/goldsproutapp/goldsprout-backend/calculations/simulation"
+	"github.com/goldsproutapp/goldsprout-backend/database"
+	"github.com/goldsproutapp/goldsprout-backend/middleware"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/goldsproutapp/goldsprout-backend/request"
+	"github.com/goldsproutapp/goldsprout-backend/request/response"
+)
+
+func Simulate(ctx *gin.Context) {
+	var query models.StockFilterQuery
+	var body models.SimulationRequest
+	if ctx.BindQuery(&query) != nil || ctx.BindJSON(&body) != nil || !simulation.IsSimulationValid(body) {
+		response.BadRequest(ctx)
+		return
+	}
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	snapshots := database.GetFilteredSnapshots(db, user, request.BuildStockFilter(query), false)
+	response.OK(ctx, simulation.Simulate(body, snapshots))
+}
+
+func RegisterSimulationRoutes(router *gin.RouterGroup) {
+	router.POST("/simulate", middleware.Authenticate("AccessPermissions"), Simulate)
+}
@@ -3,7 +3,9 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/goldsproutapp/goldsprout-backend/auth"
+	"github.com/goldsproutapp/goldsprout-backend/constants"
 	"github.com/goldsproutapp/goldsprout-backend/database"
+	"github.com/goldsproutapp/goldsprout-backend/lib/alerts"
 	"github.com/goldsproutapp/goldsprout-backend/lib/snapshots"
 	"github.com/goldsproutapp/goldsprout-backend/middleware"
 	"github.com/goldsproutapp/goldsprout-backend/models"
@@ -57,10 +59,22 @@ func CreateSnapshots(ctx *gin.Context) {
 		response.BadRequest(ctx)
 		return
 	}
+	for _, batch := range body.Batches {
+		if !middleware.TokenAllowsAccount(ctx, batch.AccountID) {
+			response.Forbidden(ctx)
+			return
+		}
+	}
 	out, err := snapshots.CreateSnapshots(user, db, body)
 	if err != nil {
 		response.SendError(ctx, err)
+		return
+	}
+	userIDs := util.NewHashSet[uint]()
+	for _, snapshot := range out {
+		userIDs.Add(snapshot.UserID)
 	}
+	util.Background("drift evaluation", func() { alerts.EvaluateDrift(db, userIDs.Items()) })
 	response.Created(ctx, out)
 }
 
@@ -78,7 +92,8 @@ func DeleteSnapshot(ctx *gin.Context) {
 		response.NotFound(ctx)
 		return
 	}
-	if !auth.HasAccessPerm(user, snapshot.UserID, false, true, false) {
+	if !auth.HasAccessPerm(user, snapshot.UserID, false, true, false) ||
+		!middleware.TokenAllowsAccount(ctx, snapshot.AccountID) {
 		response.Forbidden(ctx)
 		return
 	}
@@ -89,6 +104,8 @@ func DeleteSnapshot(ctx *gin.Context) {
 func RegisterSnapshotRoutes(router *gin.RouterGroup) {
 	router.GET("/snapshots/latest", middleware.Authenticate("AccessPermissions"), GetLatestSnapshotList)
 	router.GET("/snapshots/for_stock", middleware.Authenticate("AccessPermissions"), GetSnapshotForStock)
-	router.POST("/snapshots", middleware.Authenticate("AccessPermissions"), CreateSnapshots)
-	router.DELETE("/snapshots/:id", middleware.Authenticate("AccessPermissions"), DeleteSnapshot)
+	router.POST("/snapshots", middleware.PermitTokenScope(constants.API_SCOPE_SNAPSHOT_WRITE),
+		middleware.PermitRestrictedTokens(), middleware.Authenticate("AccessPermissions"), CreateSnapshots)
+	router.DELETE("/snapshots/:id", middleware.PermitTokenScope(constants.API_SCOPE_SNAPSHOT_WRITE),
+		middleware.PermitRestrictedTokens(), middleware.Authenticate("AccessPermissions"), DeleteSnapshot)
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/goldsproutapp/goldsprout-backend/calculations/split"
 	"github.com/goldsproutapp/goldsprout-backend/database"
+	"github.com/goldsproutapp/goldsprout-backend/lib/extraction/times"
 	"github.com/goldsproutapp/goldsprout-backend/lib/processing"
 	"github.com/goldsproutapp/goldsprout-backend/middleware"
 	"github.com/goldsproutapp/goldsprout-backend/models"
@@ -79,7 +80,7 @@ func SplitHistory(ctx *gin.Context) {
 		response.NotFound(ctx)
 		return
 	}
-	snapshotMapMerged, _ := processing.CreateMergedSnapshotMap(allSnapshots)
+	snapshotMapMerged, _ := processing.CreateMergedSnapshotMap(allSnapshots, times.UserYearStart(user))
 	out := map[string]map[time.Time]decimal.Decimal{}
 	acrossKey := util.Assign(query.Item).If(query.Compare == "all").Else(query.Across)
 	for t, s := range snapshotMapMerged {
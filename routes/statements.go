@@ -0,0 +1,95 @@
+package routes
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goldsproutapp/goldsprout-backend/auth"
+	"github.com/goldsproutapp/goldsprout-backend/calculations/reports"
+	"github.com/goldsproutapp/goldsprout-backend/constants"
+	"github.com/goldsproutapp/goldsprout-backend/database"
+	"github.com/goldsproutapp/goldsprout-backend/lib/extraction/times"
+	"github.com/goldsproutapp/goldsprout-backend/lib/statements"
+	"github.com/goldsproutapp/goldsprout-backend/middleware"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/goldsproutapp/goldsprout-backend/request/response"
+	"github.com/goldsproutapp/goldsprout-backend/util"
+)
+
+const pdfContentType = "application/pdf"
+
+func statementFilename(statement models.Statement) string {
+	return fmt.Sprintf("statement-%d-%s-%s.pdf", statement.UserID, statement.Period, statement.Start.Format(constants.ISO8601))
+}
+
+func RenderStatement(ctx *gin.Context) {
+	var query models.StatementRequestQuery
+	if ctx.BindQuery(&query) != nil || !slices.Contains(constants.STATEMENT_PERIODS, query.Period) {
+		response.BadRequest(ctx)
+		return
+	}
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	if query.User == 0 {
+		query.User = user.ID
+	}
+	if !auth.HasAccessPerm(user, query.User, true, false, false) {
+		response.Forbidden(ctx)
+		return
+	}
+	var owner models.User
+	if !database.Exists(db.Model(&models.User{}).Where("id = ?", query.User).Preload("AccessPermissions").First(&owner)) {
+		response.NotFound(ctx)
+		return
+	}
+	date := time.Unix(query.Date, 0)
+	if query.Date == 0 {
+		date, _ = reports.LastCompletePeriod(query.Period, times.UserYearStart(owner), time.Now())
+	}
+	statement, err := statements.Generate(db, user, owner, query.Household, query.Period, date)
+	if err != nil {
+		response.InternalServerError(ctx)
+		return
+	}
+	response.BinaryFileOK(ctx, statementFilename(statement), pdfContentType, statement.Content)
+}
+
+func GetStatements(ctx *gin.Context) {
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	visible, err := database.GetVisibleStatements(db, user)
+	if err != nil {
+		response.BadRequest(ctx)
+		return
+	}
+	response.OK(ctx, visible)
+}
+
+func DownloadStatement(ctx *gin.Context) {
+	errs := []error{}
+	id := util.ParseUint(ctx.Param("id"), &errs)
+	if len(errs) > 0 {
+		response.BadRequest(ctx)
+		return
+	}
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	statement, err := database.GetStatement(db, id)
+	if err != nil {
+		response.NotFound(ctx)
+		return
+	}
+	if !database.CanViewStatement(db, user, statement) {
+		response.Forbidden(ctx)
+		return
+	}
+	response.BinaryFileOK(ctx, statementFilename(statement), pdfContentType, statement.Content)
+}
+
+func RegisterStatementRoutes(router *gin.RouterGroup) {
+	router.GET("/statements", middleware.Authenticate("AccessPermissions"), GetStatements)
+	router.GET("/statements/render", middleware.Authenticate("AccessPermissions"), RenderStatement)
+	router.GET("/statements/:id", middleware.Authenticate("AccessPermissions"), DownloadStatement)
+}
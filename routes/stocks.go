@@ -2,12 +2,17 @@ package routes
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/goldsproutapp/goldsprout-backend/auth"
+	"github.com/goldsproutapp/goldsprout-backend/calculations/positions"
 	"github.com/goldsproutapp/goldsprout-backend/database"
 	"github.com/goldsproutapp/goldsprout-backend/middleware"
 	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/goldsproutapp/goldsprout-backend/request"
 	"github.com/goldsproutapp/goldsprout-backend/request/response"
+	"github.com/goldsproutapp/goldsprout-backend/util"
 	"github.com/shopspring/decimal"
 )
 
@@ -106,6 +111,7 @@ func MergeStocks(ctx *gin.Context) {
 				otherUS.CurrentlyHeld = true
 				db.Save(&otherUS)
 			}
+			database.MergeUserStockTags(db, us.ID, otherUS.ID)
 			db.Delete(&us)
 		}
 	}
@@ -114,9 +120,64 @@ func MergeStocks(ctx *gin.Context) {
 	response.NoContent(ctx)
 }
 
+func GetPositions(ctx *gin.Context) {
+	var query models.StockFilterQuery
+	if ctx.BindQuery(&query) != nil {
+		response.BadRequest(ctx)
+		return
+	}
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	filter := request.BuildStockFilter(query)
+	// The full history is needed for the cost basis.
+	filter.LowerDate = time.Unix(0, 0)
+	filter.UpperDate = time.Unix(0, 0)
+	snapshots := database.GetFilteredSnapshots(db, user, filter, false)
+	userStocks := database.GetVisibleStockList(user, db, false)
+	response.OK(ctx, positions.CalculatePositions(db, userStocks, snapshots))
+}
+
+func GetTags(ctx *gin.Context) {
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	response.OK(ctx, database.GetVisibleTags(db, user))
+}
+
+func SetHoldingTags(ctx *gin.Context) {
+	errs := []error{}
+	id := util.ParseUint(ctx.Param("id"), &errs)
+	if len(errs) > 0 {
+		response.BadRequest(ctx)
+		return
+	}
+	var body models.SetTagsRequest
+	if ctx.BindJSON(&body) != nil {
+		response.BadRequest(ctx)
+		return
+	}
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	userStock, err := database.GetUserStockByID(db, id)
+	if err != nil {
+		response.NotFound(ctx)
+		return
+	}
+	if !auth.HasAccessPerm(user, userStock.UserID, false, true, false) {
+		response.Forbidden(ctx)
+		return
+	}
+	tags := database.NormaliseTags(body.Tags)
+	database.SetUserStockTags(db, userStock.ID, tags)
+	userStock.Tags = tags
+	response.OK(ctx, userStock)
+}
+
 func RegisterStockRoutes(router *gin.RouterGroup) {
 	router.GET("/holdings", middleware.Authenticate("AccessPermissions"), GetHoldings)
 	router.GET("/stocks", middleware.Authenticate("AccessPermissions"), GetAllStocks)
 	router.PUT("/stocks", middleware.Authenticate("AccessPermissions"), UpdateStock)
 	router.POST("/stocks/merge", middleware.Authenticate("AccessPermissions"), MergeStocks)
+	router.GET("/positions", middleware.Authenticate("AccessPermissions"), GetPositions)
+	router.GET("/tags", middleware.Authenticate("AccessPermissions"), GetTags)
+	router.PUT("/holdings/:id/tags", middleware.Authenticate("AccessPermissions"), SetHoldingTags)
 }
@@ -0,0 +1,197 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/goldsproutapp/goldsprout-backend/auth"
+	"github.com/goldsproutapp/goldsprout-backend/calculations/rebalance"
+	"github.com/goldsproutapp/goldsprout-backend/constants"
+	"github.com/goldsproutapp/goldsprout-backend/database"
+	"github.com/goldsproutapp/goldsprout-backend/middleware"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/goldsproutapp/goldsprout-backend/request/response"
+	"github.com/goldsproutapp/goldsprout-backend/util"
+	"github.com/shopspring/decimal"
+)
+
+func targetTolerance(body models.AllocationTargetRequest) decimal.Decimal {
+	if body.Tolerance == nil {
+		return decimal.NewFromInt(constants.DEFAULT_DRIFT_TOLERANCE)
+	}
+	return *body.Tolerance
+}
+
+func GetTargets(ctx *gin.Context) {
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	targets, err := database.GetVisibleTargets(db, user)
+	if err != nil {
+		response.BadRequest(ctx)
+		return
+	}
+	response.OK(ctx, targets)
+}
+
+func CreateTarget(ctx *gin.Context) {
+	var body models.AllocationTargetRequest
+	if ctx.BindJSON(&body) != nil || !rebalance.IsTargetValid(body) {
+		response.BadRequest(ctx)
+		return
+	}
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	if !auth.HasAccessPerm(user, body.UserID, false, true, false) {
+		response.Forbidden(ctx)
+		return
+	}
+	target := models.AllocationTarget{
+		UserID:    body.UserID,
+		Name:      body.Name,
+		Dimension: body.Dimension,
+		Household: body.Household,
+		Targets:   body.Targets,
+
+		Tolerance:   targetTolerance(body),
+		EmailAlerts: body.EmailAlerts,
+	}
+	if db.Create(&target).Error != nil {
+		response.BadRequest(ctx)
+		return
+	}
+	response.Created(ctx, target)
+}
+
+func UpdateTarget(ctx *gin.Context) {
+	errs := []error{}
+	id := util.ParseUint(ctx.Param("id"), &errs)
+	var body models.AllocationTargetRequest
+	if len(errs) > 0 || ctx.BindJSON(&body) != nil || !rebalance.IsTargetValid(body) {
+		response.BadRequest(ctx)
+		return
+	}
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	target, err := database.GetTarget(db, id)
+	if err != nil {
+		response.NotFound(ctx)
+		return
+	}
+	if !auth.HasAccessPerm(user, target.UserID, false, true, false) ||
+		!auth.HasAccessPerm(user, body.UserID, false, true, false) {
+		response.Forbidden(ctx)
+		return
+	}
+	target.UserID = body.UserID
+	target.Name = body.Name
+	target.Dimension = body.Dimension
+	target.Household = body.Household
+	target.Targets = body.Targets
+	target.Tolerance = targetTolerance(body)
+	target.EmailAlerts = body.EmailAlerts
+	db.Save(&target)
+	response.OK(ctx, target)
+}
+
+func DeleteTarget(ctx *gin.Context) {
+	errs := []error{}
+	id := util.ParseUint(ctx.Param("id"), &errs)
+	if len(errs) > 0 {
+		response.BadRequest(ctx)
+		return
+	}
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	target, err := database.GetTarget(db, id)
+	if err != nil {
+		response.NotFound(ctx)
+		return
+	}
+	if !auth.HasAccessPerm(user, target.UserID, false, true, false) {
+		response.Forbidden(ctx)
+		return
+	}
+	if database.DeleteTarget(db, target) != nil {
+		response.InternalServerError(ctx)
+		return
+	}
+	response.NoContent(ctx)
+}
+
+func Rebalance(ctx *gin.Context) {
+	var query models.RebalanceRequestQuery
+	if ctx.BindQuery(&query) != nil {
+		response.BadRequest(ctx)
+		return
+	}
+	contribution := decimal.NewFromInt(0)
+	if query.Contribution != "" {
+		errs := []error{}
+		contribution = util.ParseDecimal(query.Contribution, &errs)
+		if len(errs) > 0 || contribution.IsNegative() {
+			response.BadRequest(ctx)
+			return
+		}
+	}
+	if query.ContributionsOnly && !contribution.IsPositive() {
+		response.BadRequest(ctx)
+		return
+	}
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	target, err := database.GetTarget(db, query.Target)
+	if err != nil {
+		response.NotFound(ctx)
+		return
+	}
+	if !auth.HasAccessPerm(user, target.UserID, true, false, false) {
+		response.Forbidden(ctx)
+		return
+	}
+	filter := database.StockFilter{Users: database.GetTargetUsers(db, target)}
+	snapshots := database.GetFilteredSnapshots(db, user, filter, false)
+	result := rebalance.CalculateRebalance(target, snapshots, contribution, query.ContributionsOnly)
+	response.OK(ctx, result)
+}
+
+func GetAlerts(ctx *gin.Context) {
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	alerts, err := database.GetVisibleAlerts(db, user, ctx.Query("active_only") == "true")
+	if err != nil {
+		response.BadRequest(ctx)
+		return
+	}
+	response.OK(ctx, alerts)
+}
+
+func DismissAlert(ctx *gin.Context) {
+	errs := []error{}
+	id := util.ParseUint(ctx.Param("id"), &errs)
+	if len(errs) > 0 {
+		response.BadRequest(ctx)
+		return
+	}
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	alert, err := database.GetAlert(db, id)
+	if err != nil {
+		response.NotFound(ctx)
+		return
+	}
+	if !auth.HasAccessPerm(user, alert.UserID, false, true, false) {
+		response.Forbidden(ctx)
+		return
+	}
+	alert.Dismissed = true
+	db.Save(&alert)
+	response.NoContent(ctx)
+}
+
+func RegisterTargetRoutes(router *gin.RouterGroup) {
+	router.GET("/targets", middleware.Authenticate("AccessPermissions"), GetTargets)
+	router.POST("/targets", middleware.Authenticate("AccessPermissions"), CreateTarget)
+	router.PUT("/targets/:id", middleware.Authenticate("AccessPermissions"), UpdateTarget)
+	router.DELETE("/targets/:id", middleware.Authenticate("AccessPermissions"), DeleteTarget)
+	router.GET("/rebalance", middleware.Authenticate("AccessPermissions"), Rebalance)
+	router.GET("/alerts", middleware.Authenticate("AccessPermissions"), GetAlerts)
+	router.DELETE("/alerts/:id", middleware.Authenticate("AccessPermissions"), DismissAlert)
+}
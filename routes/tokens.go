@@ -0,0 +1,145 @@
+package routes
+
+import (
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/goldsproutapp/goldsprout-backend/auth"
+	"github.com/goldsproutapp/goldsprout-backend/constants"
+	"github.com/goldsproutapp/goldsprout-backend/database"
+	"github.com/goldsproutapp/goldsprout-backend/middleware"
+	"github.com/goldsproutapp/goldsprout-backend/models"
+	"github.com/goldsproutapp/goldsprout-backend/request/response"
+	"github.com/goldsproutapp/goldsprout-backend/util"
+)
+
+// Tokens are managed from interactive sessions only, so that a token cannot create or revoke others.
+func interactiveSession(ctx *gin.Context) bool {
+	session := middleware.GetSession(ctx)
+	return !session.IsTokenSession && !session.IsDemoSession
+}
+
+func GetAPITokens(ctx *gin.Context) {
+	if !interactiveSession(ctx) {
+		response.Forbidden(ctx)
+		return
+	}
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	tokens, err := database.GetUserAPITokens(db, user.ID)
+	if err != nil {
+		response.BadRequest(ctx)
+		return
+	}
+	response.OK(ctx, tokens)
+}
+
+func CreateAPIToken(ctx *gin.Context) {
+	if !interactiveSession(ctx) {
+		response.Forbidden(ctx)
+		return
+	}
+	var body models.APITokenRequest
+	if ctx.BindJSON(&body) != nil || !slices.Contains(constants.API_SCOPES, body.Scope) {
+		response.BadRequest(ctx)
+		return
+	}
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	if body.Scope == constants.API_SCOPE_ADMIN && !user.IsAdmin {
+		response.Forbidden(ctx)
+		return
+	}
+	var expiresAt *time.Time
+	if body.ExpiresAt != 0 {
+		t := time.Unix(body.ExpiresAt, 0)
+		if t.Before(time.Now()) {
+			response.BadRequest(ctx)
+			return
+		}
+		expiresAt = &t
+	}
+	for _, id := range body.Accounts {
+		account, err := database.GetAccount(db, id)
+		if err != nil {
+			response.BadRequest(ctx)
+			return
+		}
+		if !auth.HasAccessPerm(user, account.UserID, false, true, false) {
+			response.Forbidden(ctx)
+			return
+		}
+	}
+	secret := auth.GenerateAPIToken()
+	token := models.APIToken{
+		UserID:    user.ID,
+		Name:      body.Name,
+		TokenHash: auth.Hash(secret),
+		Scope:     body.Scope,
+		ExpiresAt: expiresAt,
+		Accounts:  body.Accounts,
+	}
+	if token.Accounts == nil {
+		token.Accounts = []uint{}
+	}
+	db.Create(&token)
+	// The token itself is only ever returned here.
+	response.Created(ctx, gin.H{"token": secret, "data": token})
+}
+
+// The most recent requests made with the token.
+func GetAPITokenUses(ctx *gin.Context) {
+	if !interactiveSession(ctx) {
+		response.Forbidden(ctx)
+		return
+	}
+	errs := []error{}
+	id := util.ParseUint(ctx.Param("id"), &errs)
+	if len(errs) > 0 {
+		response.BadRequest(ctx)
+		return
+	}
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	token, err := database.GetUserAPIToken(db, user.ID, id)
+	if err != nil {
+		response.NotFound(ctx)
+		return
+	}
+	uses, err := database.GetAPITokenUses(db, token.ID, constants.API_TOKEN_USE_LOG_LIMIT)
+	if err != nil {
+		response.BadRequest(ctx)
+		return
+	}
+	response.OK(ctx, uses)
+}
+
+func RevokeAPIToken(ctx *gin.Context) {
+	if !interactiveSession(ctx) {
+		response.Forbidden(ctx)
+		return
+	}
+	errs := []error{}
+	id := util.ParseUint(ctx.Param("id"), &errs)
+	if len(errs) > 0 {
+		response.BadRequest(ctx)
+		return
+	}
+	db := middleware.GetDB(ctx)
+	user := middleware.GetUser(ctx)
+	token, err := database.GetUserAPIToken(db, user.ID, id)
+	if err != nil {
+		response.NotFound(ctx)
+		return
+	}
+	database.DeleteAPIToken(db, token)
+	response.NoContent(ctx)
+}
+
+func RegisterTokenRoutes(router *gin.RouterGroup) {
+	router.GET("/tokens", middleware.Authenticate(), GetAPITokens)
+	router.POST("/tokens", middleware.Authenticate(), CreateAPIToken)
+	router.GET("/tokens/:id/uses", middleware.Authenticate(), GetAPITokenUses)
+	router.DELETE("/tokens/:id", middleware.Authenticate(), RevokeAPIToken)
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/goldsproutapp/goldsprout-backend/calculations/trends"
 	"github.com/goldsproutapp/goldsprout-backend/database"
+	"github.com/goldsproutapp/goldsprout-backend/lib/extraction/times"
 	"github.com/goldsproutapp/goldsprout-backend/middleware"
 	"github.com/goldsproutapp/goldsprout-backend/models"
 	"github.com/goldsproutapp/goldsprout-backend/request"
@@ -19,12 +20,14 @@ func Trends(ctx *gin.Context) {
 		response.BadRequest(ctx)
 		return
 	}
+	user := middleware.GetUser(ctx)
 	info := trends.PerformanceQueryInfo{
 		TargetKey:  query.Of,
 		AgainstKey: query.For,
 		TimeKey:    query.Over,
 		MetricKey:  query.Compare,
 		LatestOnly: query.LatestOnly,
+		FiscalYear: times.UserYearStart(user),
 	}
 	filter := request.BuildStockFilter(query.StockFilterQuery)
 	if !trends.IsPerformanceQueryValid(info) {
@@ -33,7 +36,6 @@ func Trends(ctx *gin.Context) {
 	}
 	trends.SetQueryMeta(&info)
 	db := middleware.GetDB(ctx)
-	user := middleware.GetUser(ctx)
 	snapshots := database.GetFilteredSnapshots(db, user, filter, info.Meta.PermitLimited)
 	groupedInfo, timePeriods, clickThrough := trends.ProcessSnapshots(snapshots, info)
 	result := trends.BuildSummary(groupedInfo, info, timePeriods, clickThrough)
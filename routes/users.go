@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/goldsproutapp/goldsprout-backend/auth"
 	"github.com/goldsproutapp/goldsprout-backend/database"
+	"github.com/goldsproutapp/goldsprout-backend/lib/extraction/times"
 	"github.com/goldsproutapp/goldsprout-backend/middleware"
 	"github.com/goldsproutapp/goldsprout-backend/models"
 	"github.com/goldsproutapp/goldsprout-backend/request/response"
@@ -45,6 +46,12 @@ func UpdateUserInfo(ctx *gin.Context) {
 		response.BadRequest(ctx)
 		return
 	}
+	if body.FiscalYearStart != nil && *body.FiscalYearStart != "" {
+		if _, err := times.ParseYearStart(*body.FiscalYearStart); err != nil {
+			response.BadRequest(ctx)
+			return
+		}
+	}
 	user.ApplyUpdate(body)
 	db.Save(&user)
 	response.OK(ctx, user)
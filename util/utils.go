@@ -111,3 +111,19 @@ func UpdateMap[K comparable, V any](dst map[K]V, src map[K]V) map[K]V {
 
 	return out
 }
+
+// Run fn in a new goroutine. A panic is logged rather than crashing the server, as gin's
+// recovery only covers the request's own goroutine.
+func Background(name string, fn func()) {
+	go Recovered(name, fn)
+}
+
+// Run fn, logging rather than propagating a panic.
+func Recovered(name string, fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Printf("Error in %s: %v\n", name, r)
+		}
+	}()
+	fn()
+}